@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var accessCmd = &cobra.Command{
+	Use:   "access",
+	Short: "Report on team access across the organization",
+}
+
+var accessMatrixCmd = &cobra.Command{
+	Use:   "matrix",
+	Short: "Show effective team access for every workspace",
+	Long: `Show effective team access for every workspace.
+
+Combines direct team workspace access, team project access inherited by the
+project's workspaces, and organization-level team permissions into a
+workspace x team matrix. Each cell is the highest level the team gets, with
+where it comes from:
+
+  workspace  direct team access on the workspace
+  project    team access on the workspace's project (maintain maps to admin)
+  org        the owners team, or "manage workspaces"/"manage projects"
+
+Use --csv for a spreadsheet-friendly export, or --json for one object per
+workspace.`,
+	RunE: runAccessMatrix,
+}
+
+func init() {
+	accessMatrixCmd.Flags().String("tag", "", "Only workspaces with this tag")
+	accessMatrixCmd.Flags().String("project", "", "Only workspaces in this project (name or ID)")
+	accessMatrixCmd.Flags().StringSlice("team", nil, "Only these teams (names)")
+	accessMatrixCmd.Flags().Bool("csv", false, "Write the matrix as CSV")
+
+	accessCmd.AddCommand(accessMatrixCmd)
+	rootCmd.AddCommand(accessCmd)
+}
+
+// Sources of effective access.
+const (
+	accessFromWorkspace = "workspace"
+	accessFromProject   = "project"
+	accessFromOrg       = "org"
+)
+
+// accessRank orders access levels; custom sits between plan and write since
+// its effective power depends on the granular permissions.
+var accessRank = map[string]int{
+	"read":   1,
+	"plan":   2,
+	"custom": 3,
+	"write":  4,
+	"admin":  5,
+}
+
+// projectAccessToWorkspace maps a team project access level to the access it
+// grants on the project's workspaces.
+func projectAccessToWorkspace(level string) string {
+	switch level {
+	case "maintain", "admin":
+		return "admin"
+	default:
+		return level
+	}
+}
+
+type matrixTeam struct {
+	ID               string
+	Name             string
+	ManageWorkspaces bool
+	ManageProjects   bool
+}
+
+type matrixWorkspace struct {
+	ID        string
+	Name      string
+	ProjectID string
+	Project   string
+}
+
+// accessCell is one team's effective access to one workspace.
+type accessCell struct {
+	Level  string `json:"level"`
+	Source string `json:"source"`
+}
+
+// accessRow is one workspace's row in the matrix, keyed by team name.
+type accessRow struct {
+	WorkspaceID string                `json:"workspace_id"`
+	Workspace   string                `json:"workspace"`
+	ProjectID   string                `json:"project_id"`
+	Project     string                `json:"project"`
+	Teams       map[string]accessCell `json:"teams"`
+}
+
+// computeAccessMatrix resolves effective access for each workspace and team.
+// wsAccess is keyed by workspace ID then team ID; projAccess by project ID
+// then team ID.
+func computeAccessMatrix(workspaces []matrixWorkspace, teams []matrixTeam, wsAccess, projAccess map[string]map[string]string) []accessRow {
+	var rows []accessRow
+	for _, ws := range workspaces {
+		row := accessRow{
+			WorkspaceID: ws.ID,
+			Workspace:   ws.Name,
+			ProjectID:   ws.ProjectID,
+			Project:     ws.Project,
+			Teams:       map[string]accessCell{},
+		}
+		for _, t := range teams {
+			var best accessCell
+			consider := func(level, source string) {
+				if level != "" && accessRank[level] > accessRank[best.Level] {
+					best = accessCell{Level: level, Source: source}
+				}
+			}
+			if t.Name == "owners" || t.ManageWorkspaces || t.ManageProjects {
+				consider("admin", accessFromOrg)
+			}
+			consider(projectAccessToWorkspace(projAccess[ws.ProjectID][t.ID]), accessFromProject)
+			consider(wsAccess[ws.ID][t.ID], accessFromWorkspace)
+			if best.Level != "" {
+				row.Teams[t.Name] = best
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func runAccessMatrix(cmd *cobra.Command, args []string) error {
+	tag, _ := cmd.Flags().GetString("tag")
+	project, _ := cmd.Flags().GetString("project")
+	teamFilter, _ := cmd.Flags().GetStringSlice("team")
+	asCSV, _ := cmd.Flags().GetBool("csv")
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	// Teams and their organization-level permissions.
+	var teams []matrixTeam
+	if err := client.GetAllPages(fmt.Sprintf("/organizations/%s/teams?page[size]=100", org), func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a teamAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			if len(teamFilter) > 0 && !containsStr(teamFilter, a.Name) {
+				continue
+			}
+			teams = append(teams, matrixTeam{
+				ID:               r.ID,
+				Name:             a.Name,
+				ManageWorkspaces: a.OrganizationAccess.ManageWorkspaces,
+				ManageProjects:   a.OrganizationAccess.ManageProjects,
+			})
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	// Projects, for names and team project access.
+	projectNames := map[string]string{}
+	if err := client.GetAllPages(fmt.Sprintf("/organizations/%s/projects?page[size]=100", org), func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a projectAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			projectNames[r.ID] = a.Name
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	// Workspaces, narrowed by tag and project.
+	wsPath := fmt.Sprintf("/organizations/%s/workspaces?page[size]=100", org)
+	if tag != "" {
+		wsPath += "&search[tags]=" + url.QueryEscape(tag)
+	}
+	if project != "" {
+		projectID, err := resolveProjectID(project)
+		if err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		wsPath += "&filter[project][id]=" + projectID
+	}
+	var workspaces []matrixWorkspace
+	if err := client.GetAllPages(wsPath, func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a wsAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			projectID := extractRelationshipID(&r, "project")
+			workspaces = append(workspaces, matrixWorkspace{
+				ID:        r.ID,
+				Name:      a.Name,
+				ProjectID: projectID,
+				Project:   projectNames[projectID],
+			})
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].Name < workspaces[j].Name })
+
+	// Direct workspace access, one request per workspace.
+	wsAccess := map[string]map[string]string{}
+	for _, ws := range workspaces {
+		wsAccess[ws.ID] = map[string]string{}
+		path := fmt.Sprintf("/team-workspaces?filter[workspace][id]=%s", ws.ID)
+		if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+			for _, r := range page {
+				var a teamAccessAttrs
+				jsonapi.UnmarshalAttributes(&r, &a)
+				wsAccess[ws.ID][extractRelationshipID(&r, "team")] = a.Access
+			}
+		}); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	}
+
+	// Project access, only for projects that contain a listed workspace.
+	projAccess := map[string]map[string]string{}
+	for _, ws := range workspaces {
+		if ws.ProjectID == "" || projAccess[ws.ProjectID] != nil {
+			continue
+		}
+		projAccess[ws.ProjectID] = map[string]string{}
+		path := fmt.Sprintf("/team-projects?filter[project][id]=%s", ws.ProjectID)
+		if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+			for _, r := range page {
+				var a struct {
+					Access string `json:"access"`
+				}
+				jsonapi.UnmarshalAttributes(&r, &a)
+				projAccess[ws.ProjectID][extractRelationshipID(&r, "team")] = a.Access
+			}
+		}); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	}
+
+	rows := computeAccessMatrix(workspaces, teams, wsAccess, projAccess)
+
+	// Only show teams that have access somewhere in the result.
+	var columns []string
+	for _, t := range teams {
+		for _, row := range rows {
+			if _, ok := row.Teams[t.Name]; ok {
+				columns = append(columns, t.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	if asCSV {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		_ = w.Write(append([]string{"workspace", "project"}, columns...))
+		for _, row := range rows {
+			record := []string{row.Workspace, row.Project}
+			for _, team := range columns {
+				record = append(record, row.Teams[team].Level)
+			}
+			_ = w.Write(record)
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return output.NewInternalError(fmt.Sprintf("write csv: %v", err))
+		}
+		return output.RenderStream(&buf, GetOutputOptions())
+	}
+
+	opts := GetOutputOptions()
+
+	td := output.TableData{
+		Headers: append([]string{"WORKSPACE", "PROJECT"}, columns...),
+	}
+	for _, row := range rows {
+		cells := []string{row.Workspace, defaultStr(row.Project, "-")}
+		for _, team := range columns {
+			c, ok := row.Teams[team]
+			if !ok {
+				cells = append(cells, "-")
+				continue
+			}
+			cells = append(cells, fmt.Sprintf("%s (%s)", c.Level, c.Source))
+		}
+		td.Rows = append(td.Rows, cells)
+	}
+
+	return output.RenderTable(td, rows, opts)
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import "testing"
+
+func TestComputeAccessMatrix(t *testing.T) {
+	workspaces := []matrixWorkspace{
+		{ID: "ws-prod", Name: "prod", ProjectID: "prj-app", Project: "app"},
+		{ID: "ws-sandbox", Name: "sandbox", ProjectID: "prj-dev", Project: "dev"},
+	}
+	teams := []matrixTeam{
+		{ID: "team-owners", Name: "owners"},
+		{ID: "team-dev", Name: "developers"},
+		{ID: "team-sre", Name: "sre"},
+		{ID: "team-plat", Name: "platform", ManageWorkspaces: true},
+	}
+	wsAccess := map[string]map[string]string{
+		"ws-prod":    {"team-dev": "read", "team-sre": "write"},
+		"ws-sandbox": {"team-dev": "write"},
+	}
+	projAccess := map[string]map[string]string{
+		"prj-app": {"team-dev": "write", "team-sre": "maintain"},
+	}
+
+	rows := computeAccessMatrix(workspaces, teams, wsAccess, projAccess)
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	prod := rows[0].Teams
+	tests := []struct {
+		team   string
+		level  string
+		source string
+	}{
+		{"owners", "admin", accessFromOrg},
+		{"platform", "admin", accessFromOrg},
+		{"developers", "write", accessFromProject},
+		{"sre", "admin", accessFromProject},
+	}
+	for _, tt := range tests {
+		c := prod[tt.team]
+		if c.Level != tt.level || c.Source != tt.source {
+			t.Errorf("prod/%s = %+v, want %s from %s", tt.team, c, tt.level, tt.source)
+		}
+	}
+
+	sandbox := rows[1].Teams
+	if c := sandbox["developers"]; c.Level != "write" || c.Source != accessFromWorkspace {
+		t.Errorf("sandbox/developers = %+v, want write from workspace", c)
+	}
+	if _, ok := sandbox["sre"]; ok {
+		t.Errorf("sre should have no access to sandbox, got %+v", sandbox["sre"])
+	}
+}
@@ -1,31 +1,420 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
 
 var agentPoolCmd = &cobra.Command{
 	Use:     "agent-pool",
 	Aliases: []string{"ap"},
-	Short:   "View agent pools",
+	Short:   "Manage agent pools",
+	Long: `Manage agent pools, their agents and agent tokens.
+
+Agent pools accept names or IDs. An organization-scoped pool can be used by
+any workspace; otherwise only its allowed workspaces can use it.`,
 }
 
 var agentPoolListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List agent pools in an organization",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("agent-pool list")
-	},
+	RunE:  runAgentPoolList,
 }
 
 var agentPoolShowCmd = &cobra.Command{
-	Use:   "show [id]",
+	Use:   "show [pool]",
 	Short: "Show agent pool details",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("agent-pool show")
-	},
+	RunE:  runAgentPoolShow,
+}
+
+var agentPoolCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create an agent pool",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAgentPoolCreate,
+}
+
+var agentPoolUpdateCmd = &cobra.Command{
+	Use:   "update [pool]",
+	Short: "Update an agent pool",
+	Long: `Update an agent pool.
+
+--allowed-workspace replaces the pool's allowed workspaces and makes the pool
+no longer organization-scoped; pass it with an empty value to clear them.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAgentPoolUpdate,
+}
+
+var agentPoolDeleteCmd = &cobra.Command{
+	Use:   "delete [pool]",
+	Short: "Delete an agent pool",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAgentPoolDelete,
+}
+
+var agentPoolAgentsCmd = &cobra.Command{
+	Use:   "agents [pool]",
+	Short: "List the agents in a pool with their status and last ping",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAgentPoolAgents,
 }
 
 func init() {
-	agentPoolCmd.AddCommand(agentPoolListCmd, agentPoolShowCmd)
+	agentPoolListCmd.Flags().String("search", "", "Only pools whose name contains this")
+
+	agentPoolCreateCmd.Flags().Bool("organization-scoped", true, "Allow every workspace in the organization to use the pool (false with --allowed-workspace)")
+	agentPoolCreateCmd.Flags().StringSlice("allowed-workspace", nil, "Workspace allowed to use the pool (name or ID, repeatable)")
+
+	agentPoolUpdateCmd.Flags().String("name", "", "New name")
+	agentPoolUpdateCmd.Flags().Bool("organization-scoped", true, "Allow every workspace in the organization to use the pool (false with --allowed-workspace)")
+	agentPoolUpdateCmd.Flags().StringSlice("allowed-workspace", nil, "Workspace allowed to use the pool (name or ID, repeatable)")
+
+	agentPoolAgentsCmd.Flags().String("status", "", "Only agents with this status: idle, busy, unknown, exited or errored")
+
+	agentPoolCmd.AddCommand(
+		agentPoolListCmd,
+		agentPoolShowCmd,
+		agentPoolCreateCmd,
+		agentPoolUpdateCmd,
+		agentPoolDeleteCmd,
+		agentPoolAgentsCmd,
+	)
 	rootCmd.AddCommand(agentPoolCmd)
 }
+
+type agentPoolAttrs struct {
+	Name               string `json:"name"`
+	OrganizationScoped bool   `json:"organization-scoped"`
+	AgentCount         int    `json:"agent-count"`
+	CreatedAt          string `json:"created-at"`
+}
+
+type agentAttrs struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	IPAddress  string `json:"ip-address"`
+	LastPingAt string `json:"last-ping-at"`
+}
+
+// resolveAgentPoolID resolves an agent pool name or ID to an agent pool ID.
+// If the value starts with "apool-", it is returned as-is.
+func resolveAgentPoolID(client *api.Client, pool string) (string, error) {
+	if strings.HasPrefix(pool, "apool-") {
+		return pool, nil
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	path := fmt.Sprintf("/organizations/%s/agent-pools?q=%s&page[size]=100", org, url.QueryEscape(pool))
+	if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a agentPoolAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			if a.Name == pool && id == "" {
+				id = r.ID
+			}
+		}
+	}); err != nil {
+		return "", fmt.Errorf("resolve agent pool %q: %w", pool, err)
+	}
+	if id == "" {
+		return "", fmt.Errorf("resolve agent pool %q: not found in organization %s", pool, org)
+	}
+	return id, nil
+}
+
+// allowedWorkspaceIDs resolves the --allowed-workspace flag to IDs.
+func allowedWorkspaceIDs(cmd *cobra.Command) ([]string, error) {
+	workspaces, _ := cmd.Flags().GetStringSlice("allowed-workspace")
+	ids := []string{}
+	for _, ws := range workspaces {
+		if ws == "" {
+			continue
+		}
+		id, err := resolveWorkspaceID(ws)
+		if err != nil {
+			return nil, output.NewAPIError(err.Error())
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func runAgentPoolList(cmd *cobra.Command, args []string) error {
+	search, _ := cmd.Flags().GetString("search")
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/organizations/%s/agent-pools?page[size]=100", org)
+	if search != "" {
+		path += "&q=" + url.QueryEscape(search)
+	}
+
+	opts := GetOutputOptions()
+
+	type agentPoolJSON struct {
+		ID    string         `json:"id"`
+		Attrs agentPoolAttrs `json:"attributes"`
+	}
+	var jsonData []agentPoolJSON
+	td := output.TableData{
+		Headers: []string{"ID", "NAME", "ORG SCOPED", "AGENTS", "WORKSPACES", "CREATED"},
+	}
+
+	if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a agentPoolAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			td.Rows = append(td.Rows, []string{
+				r.ID, a.Name, boolStr(a.OrganizationScoped), itoa(a.AgentCount),
+				itoa(len(extractRelationshipIDs(&r, "workspaces"))), shortDate(a.CreatedAt),
+			})
+			jsonData = append(jsonData, agentPoolJSON{ID: r.ID, Attrs: a})
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
+
+func runAgentPoolShow(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	poolID, err := resolveAgentPoolID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Get("/agent-pools/"+poolID, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderAgentPool(cmd, &doc, "")
+}
+
+func runAgentPoolCreate(cmd *cobra.Command, args []string) error {
+	orgScoped, _ := cmd.Flags().GetBool("organization-scoped")
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	wsIDs, err := allowedWorkspaceIDs(cmd)
+	if err != nil {
+		return err
+	}
+	if len(wsIDs) > 0 {
+		if cmd.Flags().Changed("organization-scoped") && orgScoped {
+			return output.NewUsageError("--allowed-workspace requires --organization-scoped=false")
+		}
+		orgScoped = false
+	}
+
+	data := map[string]interface{}{
+		"type": "agent-pools",
+		"attributes": map[string]interface{}{
+			"name":                args[0],
+			"organization-scoped": orgScoped,
+		},
+	}
+	if len(wsIDs) > 0 {
+		data["relationships"] = map[string]interface{}{
+			"allowed-workspaces": relationshipData("workspaces", wsIDs),
+		}
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post("/organizations/"+org+"/agent-pools", map[string]interface{}{"data": data}, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderAgentPool(cmd, &doc, "created")
+}
+
+func runAgentPoolUpdate(cmd *cobra.Command, args []string) error {
+	attrs := map[string]interface{}{}
+	if cmd.Flags().Changed("name") {
+		name, _ := cmd.Flags().GetString("name")
+		attrs["name"] = name
+	}
+	if cmd.Flags().Changed("organization-scoped") {
+		orgScoped, _ := cmd.Flags().GetBool("organization-scoped")
+		attrs["organization-scoped"] = orgScoped
+	}
+	allowedChanged := cmd.Flags().Changed("allowed-workspace")
+	if len(attrs) == 0 && !allowedChanged {
+		return output.NewUsageError("nothing to update: pass at least one flag")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	poolID, err := resolveAgentPoolID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	data := map[string]interface{}{
+		"id":         poolID,
+		"type":       "agent-pools",
+		"attributes": attrs,
+	}
+	if allowedChanged {
+		wsIDs, err := allowedWorkspaceIDs(cmd)
+		if err != nil {
+			return err
+		}
+		if len(wsIDs) > 0 {
+			if attrs["organization-scoped"] == true {
+				return output.NewUsageError("--allowed-workspace requires --organization-scoped=false")
+			}
+			attrs["organization-scoped"] = false
+		}
+		data["relationships"] = map[string]interface{}{
+			"allowed-workspaces": relationshipData("workspaces", wsIDs),
+		}
+	}
+
+	var doc jsonapi.Document
+	if err := client.Patch("/agent-pools/"+poolID, map[string]interface{}{"data": data}, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderAgentPool(cmd, &doc, "updated")
+}
+
+func runAgentPoolDelete(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	poolID, err := resolveAgentPoolID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if err := client.Delete("/agent-pools/" + poolID); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Agent pool %s deleted\n", poolID)
+	return nil
+}
+
+func runAgentPoolAgents(cmd *cobra.Command, args []string) error {
+	status, _ := cmd.Flags().GetString("status")
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	poolID, err := resolveAgentPoolID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	opts := GetOutputOptions()
+
+	type agentJSON struct {
+		ID    string     `json:"id"`
+		Attrs agentAttrs `json:"attributes"`
+	}
+	var jsonData []agentJSON
+	td := output.TableData{
+		Headers: []string{"ID", "NAME", "STATUS", "IP ADDRESS", "LAST PING"},
+	}
+
+	if err := client.GetAllPages("/agent-pools/"+poolID+"/agents?page[size]=100", func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a agentAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			if status != "" && a.Status != status {
+				continue
+			}
+			td.Rows = append(td.Rows, []string{
+				r.ID, defaultStr(a.Name, "-"), a.Status, defaultStr(a.IPAddress, "-"), defaultStr(a.LastPingAt, "-"),
+			})
+			jsonData = append(jsonData, agentJSON{ID: r.ID, Attrs: a})
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
+
+func renderAgentPool(cmd *cobra.Command, doc *jsonapi.Document, verb string) error {
+	res, err := jsonapi.ParseSingle(doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a agentPoolAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	wsIDs := extractRelationshipIDs(res, "workspaces")
+	allowedIDs := extractRelationshipIDs(res, "allowed-workspaces")
+
+	opts := GetOutputOptions()
+
+	type agentPoolDetail struct {
+		ID                  string         `json:"id"`
+		WorkspaceIDs        []string       `json:"workspace_ids"`
+		AllowedWorkspaceIDs []string       `json:"allowed_workspace_ids"`
+		Attrs               agentPoolAttrs `json:"attributes"`
+	}
+	data := agentPoolDetail{ID: res.ID, WorkspaceIDs: wsIDs, AllowedWorkspaceIDs: allowedIDs, Attrs: a}
+
+	allowed := defaultStr(joinTags(allowedIDs), "-")
+	if a.OrganizationScoped {
+		allowed = "all (organization scoped)"
+	}
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Name", a.Name},
+			{"Organization Scoped", boolStr(a.OrganizationScoped)},
+			{"Agents", itoa(a.AgentCount)},
+			{"Allowed Workspaces", allowed},
+			{"Used By", defaultStr(joinTags(wsIDs), "-")},
+			{"Created", a.CreatedAt},
+		},
+	}
+
+	if verb != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Agent pool %s %s\n", res.ID, verb)
+	}
+	return output.RenderTable(td, data, opts)
+}
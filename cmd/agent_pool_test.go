@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+// resetAgentPoolUpdateFlags clears the agent pool update flags before and
+// after a test, as flag values otherwise carry over between Executes.
+func resetAgentPoolUpdateFlags(t *testing.T) {
+	reset := func() {
+		agentPoolUpdateCmd.Flags().VisitAll(func(f *pflag.Flag) {
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				sv.Replace(nil)
+			} else {
+				f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		})
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+type agentPoolBody struct {
+	Data struct {
+		Attributes    map[string]interface{} `json:"attributes"`
+		Relationships map[string]struct {
+			Data []struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			} `json:"data"`
+		} `json:"relationships"`
+	} `json:"data"`
+}
+
+func TestAgentPoolCreate_AllowedWorkspaces(t *testing.T) {
+	var body agentPoolBody
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/workspaces/app":
+			w.Write([]byte(`{"data":{"id":"ws-app","type":"workspaces","attributes":{"name":"app"}}}`))
+		case r.Method == "POST" && r.URL.Path == "/api/v2/organizations/test-org/agent-pools":
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(201)
+			w.Write([]byte(`{"data":{"id":"apool-1","type":"agent-pools","attributes":{"name":"fleet","organization-scoped":false},
+				"relationships":{"allowed-workspaces":{"data":[{"id":"ws-app","type":"workspaces"}]}}}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	rootCmd.SetArgs([]string{"agent-pool", "create", "fleet", "--org", "test-org", "--allowed-workspace", "app", "--json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body.Data.Attributes["name"] != "fleet" || body.Data.Attributes["organization-scoped"] != false {
+		t.Errorf("unexpected attributes: %v", body.Data.Attributes)
+	}
+	allowed := body.Data.Relationships["allowed-workspaces"].Data
+	if len(allowed) != 1 || allowed[0].ID != "ws-app" || allowed[0].Type != "workspaces" {
+		t.Errorf("unexpected allowed workspaces: %+v", allowed)
+	}
+}
+
+func TestAgentPoolUpdate_ClearsAllowedWorkspaces(t *testing.T) {
+	var raw map[string]interface{}
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/agent-pools":
+			if r.URL.Query().Get("q") != "fleet" {
+				t.Errorf("expected name query, got %q", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"data":[{"id":"apool-2","type":"agent-pools","attributes":{"name":"fleet-old"}},
+				{"id":"apool-1","type":"agent-pools","attributes":{"name":"fleet"}}]}`))
+		case r.Method == "PATCH" && r.URL.Path == "/api/v2/agent-pools/apool-1":
+			json.NewDecoder(r.Body).Decode(&raw)
+			w.Write([]byte(`{"data":{"id":"apool-1","type":"agent-pools","attributes":{"name":"fleet","organization-scoped":true}}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	rootCmd.SetArgs([]string{"ap", "update", "fleet", "--org", "test-org", "--organization-scoped", "--allowed-workspace", "", "--json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := raw["data"].(map[string]interface{})
+	rels, _ := data["relationships"].(map[string]interface{})
+	allowed, _ := rels["allowed-workspaces"].(map[string]interface{})
+	if list, ok := allowed["data"].([]interface{}); !ok || len(list) != 0 {
+		t.Errorf("expected allowed workspaces to be cleared, got %v", rels)
+	}
+	if attrs, _ := data["attributes"].(map[string]interface{}); attrs["organization-scoped"] != true || attrs["name"] != nil {
+		t.Errorf("expected only organization-scoped to change, got %v", attrs)
+	}
+}
+
+func TestAgentPoolUpdate_AllowedWorkspacesImplyNotOrgScoped(t *testing.T) {
+	var body agentPoolBody
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/workspaces/app":
+			w.Write([]byte(`{"data":{"id":"ws-app","type":"workspaces","attributes":{"name":"app"}}}`))
+		case r.Method == "PATCH" && r.URL.Path == "/api/v2/agent-pools/apool-1":
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Write([]byte(`{"data":{"id":"apool-1","type":"agent-pools","attributes":{"name":"fleet","organization-scoped":false}}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+	resetAgentPoolUpdateFlags(t)
+
+	rootCmd.SetArgs([]string{"ap", "update", "apool-1", "--org", "test-org", "--allowed-workspace", "app", "--json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body.Data.Attributes["organization-scoped"] != false {
+		t.Errorf("expected organization-scoped=false, got %v", body.Data.Attributes)
+	}
+	if allowed := body.Data.Relationships["allowed-workspaces"].Data; len(allowed) != 1 || allowed[0].ID != "ws-app" {
+		t.Errorf("unexpected allowed workspaces: %+v", allowed)
+	}
+}
+
+func TestAgentPoolUpdate_RejectsOrgScopedWithAllowedWorkspaces(t *testing.T) {
+	patched := false
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/workspaces/app":
+			w.Write([]byte(`{"data":{"id":"ws-app","type":"workspaces","attributes":{"name":"app"}}}`))
+		case r.Method == "PATCH":
+			patched = true
+			w.Write([]byte(`{"data":{"id":"apool-1","type":"agent-pools"}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+	resetAgentPoolUpdateFlags(t)
+
+	rootCmd.SetArgs([]string{"ap", "update", "apool-1", "--org", "test-org", "--organization-scoped=true", "--allowed-workspace", "app", "--json=false"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--organization-scoped=false") {
+		t.Fatalf("expected a usage error, got %v", err)
+	}
+	if patched {
+		t.Error("pool should not be updated")
+	}
+}
+
+func TestAgentPoolTokensRevoke_ValidatesIDs(t *testing.T) {
+	rootCmd.SetArgs([]string{"ap", "tokens", "revoke", "at-1", "apool-1"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected error for a non-token ID")
+	}
+}
@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var agentPoolTokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "Manage agent tokens for a pool",
+	Long: `Manage agent tokens for a pool.
+
+Agents authenticate to their pool with an agent token. The secret is only
+returned when the token is created, so store it straight away.`,
+}
+
+var agentPoolTokensListCmd = &cobra.Command{
+	Use:   "list [pool]",
+	Short: "List a pool's agent tokens",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAgentPoolTokensList,
+}
+
+var agentPoolTokensCreateCmd = &cobra.Command{
+	Use:   "create [pool]",
+	Short: "Create an agent token",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAgentPoolTokensCreate,
+}
+
+var agentPoolTokensRevokeCmd = &cobra.Command{
+	Use:   "revoke [token-id]...",
+	Short: "Revoke agent tokens",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runAgentPoolTokensRevoke,
+}
+
+func init() {
+	agentPoolTokensCreateCmd.Flags().String("description", "", "Token description (required)")
+
+	agentPoolTokensCmd.AddCommand(agentPoolTokensListCmd, agentPoolTokensCreateCmd, agentPoolTokensRevokeCmd)
+	agentPoolCmd.AddCommand(agentPoolTokensCmd)
+}
+
+type agentTokenAttrs struct {
+	Description string `json:"description"`
+	Token       string `json:"token,omitempty"`
+	CreatedAt   string `json:"created-at"`
+	LastUsedAt  string `json:"last-used-at"`
+}
+
+func runAgentPoolTokensList(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	poolID, err := resolveAgentPoolID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	opts := GetOutputOptions()
+
+	type tokenJSON struct {
+		ID    string          `json:"id"`
+		Attrs agentTokenAttrs `json:"attributes"`
+	}
+	var jsonData []tokenJSON
+	td := output.TableData{
+		Headers: []string{"ID", "DESCRIPTION", "CREATED", "LAST USED"},
+	}
+
+	if err := client.GetAllPages("/agent-pools/"+poolID+"/authentication-tokens?page[size]=100", func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a agentTokenAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			td.Rows = append(td.Rows, []string{
+				r.ID, truncateStr(a.Description, 50), shortDate(a.CreatedAt), defaultStr(shortDate(a.LastUsedAt), "never"),
+			})
+			jsonData = append(jsonData, tokenJSON{ID: r.ID, Attrs: a})
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
+
+func runAgentPoolTokensCreate(cmd *cobra.Command, args []string) error {
+	description, _ := cmd.Flags().GetString("description")
+	if strings.TrimSpace(description) == "" {
+		return output.NewUsageError("--description is required")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	poolID, err := resolveAgentPoolID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	body, err := jsonapi.WrapForCreate("authentication-tokens", map[string]interface{}{
+		"description": description,
+	})
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post("/agent-pools/"+poolID+"/authentication-tokens", body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a agentTokenAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	opts := GetOutputOptions()
+
+	type tokenDetail struct {
+		ID          string          `json:"id"`
+		AgentPoolID string          `json:"agent_pool_id"`
+		Attrs       agentTokenAttrs `json:"attributes"`
+	}
+	data := tokenDetail{ID: res.ID, AgentPoolID: poolID, Attrs: a}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Agent Pool", poolID},
+			{"Description", a.Description},
+			{"Token", a.Token},
+			{"Created", a.CreatedAt},
+		},
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Agent token %s created; it will not be shown again\n", res.ID)
+	return output.RenderTable(td, data, opts)
+}
+
+func runAgentPoolTokensRevoke(cmd *cobra.Command, args []string) error {
+	for _, id := range args {
+		if !strings.HasPrefix(id, "at-") {
+			return output.NewUsageError(fmt.Sprintf("%q is not an agent token ID (at-...)", id))
+		}
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	stderr := cmd.ErrOrStderr()
+	for _, id := range args {
+		if err := client.Delete("/authentication-tokens/" + id); err != nil {
+			return output.NewAPIError(fmt.Sprintf("revoke %s: %v", id, err))
+		}
+		fmt.Fprintf(stderr, "Agent token %s revoked\n", id)
+	}
+	return nil
+}
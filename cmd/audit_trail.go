@@ -1,26 +1,398 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
 
 var auditTrailCmd = &cobra.Command{
 	Use:     "audit-trail",
 	Aliases: []string{"audit"},
 	Short:   "View audit trail events",
+	Long: `View audit trail events.
+
+The audit trail API only accepts organization tokens. Set TFC_AUDIT_TOKEN to
+an organization token; TFC_TOKEN is used when it is not set.`,
 }
 
 var auditTrailListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List audit trail events",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("audit-trail list")
-	},
+	Long: `List audit trail events, fetching every page.
+
+With --state-file, the newest event timestamp is saved after each read and
+the next read starts from it, so repeated runs (e.g. from cron) only return
+new events. --since overrides the saved position.
+
+With --follow, polls for new events every --interval and writes them as
+NDJSON until interrupted.`,
+	RunE: runAuditTrailList,
 }
 
 func init() {
 	// List flags
-	auditTrailListCmd.Flags().String("since", "", "Filter events after this timestamp (RFC3339)")
-	auditTrailListCmd.Flags().Int("page-size", 100, "Results per page")
+	auditTrailListCmd.Flags().String("since", "", "Only events after this timestamp (RFC3339) or duration ago (e.g. 24h, 7d)")
+	auditTrailListCmd.Flags().Int("page-size", 100, "Results per page (max 1000)")
+	auditTrailListCmd.Flags().Bool("follow", false, "Keep polling for new events (NDJSON output)")
+	auditTrailListCmd.Flags().Duration("interval", time.Minute, "Polling interval with --follow")
+	auditTrailListCmd.Flags().String("state-file", "", "File recording the last seen event, for incremental reads")
+	auditTrailListCmd.Flags().Bool("ndjson", false, "Write one JSON event per line")
 
 	auditTrailCmd.AddCommand(auditTrailListCmd)
 	rootCmd.AddCommand(auditTrailCmd)
 }
+
+// auditEvent is an audit trail event. The audit trail API is not JSON:API;
+// events are plain objects, kept verbatim in Raw.
+type auditEvent struct {
+	ID        string `json:"id"`
+	Version   string `json:"version"`
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp"`
+	Auth      struct {
+		AccessorID     string `json:"accessor_id"`
+		Description    string `json:"description"`
+		Type           string `json:"type"`
+		ImpersonatorID string `json:"impersonator_id"`
+		OrganizationID string `json:"organization_id"`
+	} `json:"auth"`
+	Request struct {
+		ID string `json:"id"`
+	} `json:"request"`
+	Resource struct {
+		ID     string `json:"id"`
+		Type   string `json:"type"`
+		Action string `json:"action"`
+	} `json:"resource"`
+
+	Raw json.RawMessage `json:"-"`
+}
+
+// time returns the event timestamp, or the zero time if it does not parse.
+func (e auditEvent) time() time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, e.Timestamp)
+	return t
+}
+
+type auditTrailPage struct {
+	Data       []json.RawMessage `json:"data"`
+	Pagination struct {
+		CurrentPage int  `json:"current_page"`
+		NextPage    *int `json:"next_page"`
+		TotalPages  int  `json:"total_pages"`
+		TotalCount  int  `json:"total_count"`
+	} `json:"pagination"`
+}
+
+// fetchAuditEvents reads every page of audit events since the given
+// timestamp ("" for all retained events).
+func fetchAuditEvents(client *api.Client, since string, pageSize int) ([]auditEvent, error) {
+	var events []auditEvent
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("/organization/audit-trail?page[size]=%d&page[number]=%d", pageSize, page)
+		if since != "" {
+			path += "&since=" + url.QueryEscape(since)
+		}
+
+		var p auditTrailPage
+		if err := client.Get(path, &p); err != nil {
+			return nil, err
+		}
+		for _, raw := range p.Data {
+			var e auditEvent
+			if err := json.Unmarshal(raw, &e); err != nil {
+				return nil, fmt.Errorf("decode audit event: %w", err)
+			}
+			e.Raw = raw
+			events = append(events, e)
+		}
+
+		if p.Pagination.NextPage == nil || *p.Pagination.NextPage <= page {
+			return events, nil
+		}
+	}
+}
+
+// auditCursor is the position of an incremental audit trail read. Events at
+// exactly Since may be returned again by the API, so the IDs already seen at
+// that timestamp are kept to skip them.
+type auditCursor struct {
+	Since   string   `json:"since"`
+	SeenIDs []string `json:"seen_ids,omitempty"`
+}
+
+// loadAuditCursor reads a cursor from a state file; a missing file is an
+// empty cursor.
+func loadAuditCursor(path string) (auditCursor, error) {
+	var c auditCursor
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("parse state file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// save writes the cursor to a state file, replacing it atomically.
+func (c auditCursor) save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".audit-state-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// advance returns the events not read before, oldest first, and moves the
+// cursor past them.
+func (c *auditCursor) advance(events []auditEvent) []auditEvent {
+	since, _ := time.Parse(time.RFC3339Nano, c.Since)
+	seen := map[string]bool{}
+	for _, id := range c.SeenIDs {
+		seen[id] = true
+	}
+
+	var fresh []auditEvent
+	for _, e := range events {
+		t := e.time()
+		if (c.Since != "" && t.Before(since)) || seen[e.ID] {
+			continue
+		}
+		seen[e.ID] = true
+		fresh = append(fresh, e)
+	}
+	sort.SliceStable(fresh, func(i, j int) bool { return fresh[i].time().Before(fresh[j].time()) })
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	newest := fresh[len(fresh)-1]
+	if newest.time().After(since) || c.Since == "" {
+		c.Since = newest.Timestamp
+		c.SeenIDs = nil
+	}
+	for _, e := range fresh {
+		if e.time().Equal(newest.time()) {
+			c.SeenIDs = append(c.SeenIDs, e.ID)
+		}
+	}
+	return fresh
+}
+
+// auditTrailSince turns a --since value into a timestamp: RFC3339 is passed
+// through, a duration is counted back from now.
+func auditTrailSince(s string, now time.Time) (string, error) {
+	if _, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return s, nil
+	}
+	d, err := parseDuration(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid --since %q: want an RFC3339 timestamp or a duration like 24h or 7d", s)
+	}
+	return now.Add(-d).UTC().Format(time.RFC3339), nil
+}
+
+// writeAuditNDJSON writes events one per line, as the API returned them.
+func writeAuditNDJSON(w io.Writer, events []auditEvent) error {
+	for _, e := range events {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, e.Raw); err != nil {
+			return err
+		}
+		buf.WriteByte('\n')
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditTail reads audit events incrementally from a cursor.
+type auditTail struct {
+	Client    *api.Client
+	Cursor    auditCursor
+	StatePath string
+	PageSize  int
+	Follow    bool
+	Interval  time.Duration
+	Stderr    io.Writer
+}
+
+// run reads new events once, or until ctx is done with Follow, passing each
+// batch to emit and saving the cursor to StatePath (if set) after each batch.
+// With Follow, read errors are reported and retried.
+func (t *auditTail) run(ctx context.Context, emit func([]auditEvent) error) error {
+	for {
+		events, err := fetchAuditEvents(t.Client, t.Cursor.Since, t.PageSize)
+		if err != nil {
+			if !t.Follow {
+				return err
+			}
+			fmt.Fprintf(t.Stderr, "Audit trail read failed, retrying in %s: %v\n", t.Interval, err)
+		} else {
+			fresh := t.Cursor.advance(events)
+			if len(fresh) > 0 || !t.Follow {
+				if err := emit(fresh); err != nil {
+					return err
+				}
+			}
+			if t.StatePath != "" && len(fresh) > 0 {
+				if err := t.Cursor.save(t.StatePath); err != nil {
+					return fmt.Errorf("save state: %w", err)
+				}
+			}
+		}
+
+		if !t.Follow {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(t.Interval):
+		}
+	}
+}
+
+// newAuditTail builds an auditTail from the shared audit trail flags.
+func newAuditTail(cmd *cobra.Command) (*auditTail, error) {
+	since, _ := cmd.Flags().GetString("since")
+	pageSize, _ := cmd.Flags().GetInt("page-size")
+	follow, _ := cmd.Flags().GetBool("follow")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	statePath, _ := cmd.Flags().GetString("state-file")
+
+	if pageSize < 1 || pageSize > 1000 {
+		return nil, output.NewUsageError("--page-size must be between 1 and 1000")
+	}
+	if follow && interval <= 0 {
+		return nil, output.NewUsageError("--interval must be positive")
+	}
+
+	var cursor auditCursor
+	if statePath != "" {
+		var err error
+		if cursor, err = loadAuditCursor(statePath); err != nil {
+			return nil, output.NewUsageError(err.Error())
+		}
+	}
+	if since != "" {
+		ts, err := auditTrailSince(since, time.Now())
+		if err != nil {
+			return nil, output.NewUsageError(err.Error())
+		}
+		cursor = auditCursor{Since: ts}
+	}
+
+	client, err := newAuditClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &auditTail{
+		Client:    client,
+		Cursor:    cursor,
+		StatePath: statePath,
+		PageSize:  pageSize,
+		Follow:    follow,
+		Interval:  interval,
+		Stderr:    cmd.ErrOrStderr(),
+	}, nil
+}
+
+func runAuditTrailList(cmd *cobra.Command, args []string) error {
+	ndjson, _ := cmd.Flags().GetBool("ndjson")
+
+	tail, err := newAuditTail(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if tail.Follow {
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(tail.Stderr, "Following audit trail every %s (Ctrl-C to stop)\n", tail.Interval)
+		if err := tail.run(ctx, func(events []auditEvent) error {
+			return writeAuditNDJSON(out, events)
+		}); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		return nil
+	}
+
+	// Render inside emit so that the state file only moves past events once
+	// they have been written.
+	var renderErr error
+	if err := tail.run(ctx, func(events []auditEvent) error {
+		renderErr = renderAuditEvents(tail.Stderr, events, ndjson)
+		return renderErr
+	}); err != nil {
+		if renderErr != nil {
+			return renderErr
+		}
+		return output.NewAPIError(err.Error())
+	}
+	return nil
+}
+
+// renderAuditEvents writes events as NDJSON or a table.
+func renderAuditEvents(stderr io.Writer, events []auditEvent, ndjson bool) error {
+	fmt.Fprintf(stderr, "%d events\n", len(events))
+
+	opts := GetOutputOptions()
+	if ndjson {
+		var buf bytes.Buffer
+		if err := writeAuditNDJSON(&buf, events); err != nil {
+			return output.NewInternalError(err.Error())
+		}
+		return output.RenderStream(&buf, opts)
+	}
+
+	jsonData := []json.RawMessage{}
+	td := output.TableData{
+		Headers: []string{"TIMESTAMP", "ACTION", "RESOURCE TYPE", "RESOURCE ID", "ACTOR"},
+	}
+	for _, e := range events {
+		td.Rows = append(td.Rows, []string{
+			e.Timestamp, e.Resource.Action, e.Resource.Type, defaultStr(e.Resource.ID, "-"),
+			defaultStr(e.Auth.Description, e.Auth.AccessorID),
+		})
+		jsonData = append(jsonData, e.Raw)
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
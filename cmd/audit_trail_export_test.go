@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+)
+
+func TestFlattenAuditEvent(t *testing.T) {
+	e := testAuditEvent("ae-1", "2026-01-01T10:00:00Z", "create")
+	flat, err := flattenAuditEvent(e.Raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flat["auth.accessor_id"] != "user-1" || flat["resource.action"] != "create" || flat["request.id"] != "req-ae-1" {
+		t.Errorf("unexpected flattened event: %v", flat)
+	}
+	if _, ok := flat["auth.impersonator_id"]; ok {
+		t.Error("null fields should be dropped")
+	}
+	if _, ok := flat["auth"]; ok {
+		t.Error("nested objects should not be kept")
+	}
+}
+
+func TestFormatCEF(t *testing.T) {
+	e := testAuditEvent("ae-1", "2026-01-01T10:00:00Z", "destroy")
+	e.Auth.Description = "ali=ce"
+	line := formatCEF(e)
+
+	if !strings.HasPrefix(line, "CEF:0|HashiCorp|Terraform Cloud|") {
+		t.Errorf("unexpected header: %s", line)
+	}
+	for _, want := range []string{"|workspace:destroy|workspace destroy|6|", "rt=1767261600000", `suser=ali\=ce`, "cs2=ws-1", "externalId=ae-1"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in %s", want, line)
+		}
+	}
+}
+
+func TestFormatSyslog(t *testing.T) {
+	e := testAuditEvent("ae-1", "2026-01-01T10:00:00Z", "create")
+	flat, _ := flattenAuditEvent(e.Raw)
+	flat["resource.meta.note"] = `a "quoted" ]value`
+	line := formatSyslog(e, flat, "build host")
+
+	if !strings.HasPrefix(line, "<134>1 2026-01-01T10:00:00.000000Z build_host tfc - workspace.create [tfc@32473 ") {
+		t.Errorf("unexpected syslog header: %s", line)
+	}
+	for _, want := range []string{`auth.accessor_id="user-1"`, `resource.meta.note="a \"quoted\" \]value"`, "] alice create workspace ws-1"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected %q in %s", want, line)
+		}
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	for in, want := range map[string]int64{"512": 512, "64KB": 64 << 10, "100mb": 100 << 20, "1GB": 1 << 30, "0": 0} {
+		if got, err := parseByteSize(in); err != nil || got != want {
+			t.Errorf("parseByteSize(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+	if _, err := parseByteSize("big"); err == nil {
+		t.Error("expected error for invalid size")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	rf, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"event-1\n", "event-2\n", "event-3\n", "event-4\n"} {
+		if _, err := rf.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rf.Close()
+
+	for file, want := range map[string]string{path: "event-4\n", path + ".1": "event-3\n", path + ".2": "event-2\n"} {
+		if got, _ := os.ReadFile(file); string(got) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(file), got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Error("expected only --max-files rotated files to be kept")
+	}
+}
+
+func TestAuditEventFilter(t *testing.T) {
+	f := auditEventFilter{ResourceTypes: []string{"workspace"}, Actions: []string{"destroy"}}
+	if f.match(testAuditEvent("ae-1", "2026-01-01T10:00:00Z", "create")) {
+		t.Error("create should be filtered out")
+	}
+	if !f.match(testAuditEvent("ae-2", "2026-01-01T10:00:00Z", "destroy")) {
+		t.Error("destroy should match")
+	}
+}
+
+func TestDialSyslogEndpoint_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		received <- line
+	}()
+
+	u, err := parseSyslogEndpoint("tcp://" + ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := dialSyslogEndpoint(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, _ := auditLine(testAuditEvent("ae-1", "2026-01-01T10:00:00Z", "create"), "syslog", false, "host")
+	conn.Write(line)
+	conn.Close()
+
+	if got := <-received; !strings.HasPrefix(got, "<134>1 ") || !strings.HasSuffix(got, "\n") {
+		t.Errorf("unexpected line received: %q", got)
+	}
+	if _, err := parseSyslogEndpoint("http://example.com"); err == nil {
+		t.Error("expected error for unsupported scheme")
+	}
+}
+
+func TestAuditTrailExport_ExitCodes(t *testing.T) {
+	t.Setenv("TFC_TOKEN", "test-token")
+	missing := filepath.Join(t.TempDir(), "missing.sock")
+	defer auditTrailExportCmd.Flags().Set("endpoint", "")
+
+	tests := []struct {
+		name     string
+		endpoint string
+		code     int
+	}{
+		{"bad scheme", "http://example.com", 2},
+		{"dial failure", "unix://" + missing, 1},
+	}
+	for _, tt := range tests {
+		rootCmd.SetArgs([]string{"audit-trail", "export", "--endpoint", tt.endpoint})
+		err := rootCmd.Execute()
+		var se *output.StructuredError
+		if !errors.As(err, &se) || se.ExitCode != tt.code {
+			t.Errorf("%s: expected exit code %d, got %v", tt.name, tt.code, err)
+		}
+	}
+}
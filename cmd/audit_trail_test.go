@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testAuditEvent(id, ts, action string) auditEvent {
+	raw := fmt.Sprintf(`{"id":%q,"version":"0","type":"Resource","timestamp":%q,
+		"auth":{"accessor_id":"user-1","description":"alice","type":"Client","impersonator_id":null,"organization_id":"org-1"},
+		"request":{"id":"req-%s"},"resource":{"id":"ws-1","type":"workspace","action":%q,"meta":null}}`, id, ts, id, action)
+	var e auditEvent
+	json.Unmarshal([]byte(raw), &e)
+	e.Raw = json.RawMessage(raw)
+	return e
+}
+
+func TestAuditCursorAdvance(t *testing.T) {
+	var c auditCursor
+	fresh := c.advance([]auditEvent{
+		testAuditEvent("ae-2", "2026-01-01T10:00:05Z", "update"),
+		testAuditEvent("ae-1", "2026-01-01T10:00:00Z", "create"),
+		testAuditEvent("ae-3", "2026-01-01T10:00:05Z", "destroy"),
+	})
+	if len(fresh) != 3 || fresh[0].ID != "ae-1" {
+		t.Fatalf("expected 3 events oldest first, got %+v", fresh)
+	}
+	if c.Since != "2026-01-01T10:00:05Z" || len(c.SeenIDs) != 2 {
+		t.Fatalf("unexpected cursor: %+v", c)
+	}
+
+	// The API returns events at the cursor timestamp again.
+	fresh = c.advance([]auditEvent{
+		testAuditEvent("ae-2", "2026-01-01T10:00:05Z", "update"),
+		testAuditEvent("ae-3", "2026-01-01T10:00:05Z", "destroy"),
+		testAuditEvent("ae-4", "2026-01-01T10:00:05Z", "read"),
+	})
+	if len(fresh) != 1 || fresh[0].ID != "ae-4" {
+		t.Fatalf("expected only ae-4, got %+v", fresh)
+	}
+	if c.Since != "2026-01-01T10:00:05Z" || len(c.SeenIDs) != 3 {
+		t.Errorf("expected seen IDs to accumulate at the same timestamp, got %+v", c)
+	}
+
+	fresh = c.advance([]auditEvent{testAuditEvent("ae-5", "2026-01-01T11:00:00Z", "create")})
+	if len(fresh) != 1 || c.Since != "2026-01-01T11:00:00Z" || len(c.SeenIDs) != 1 {
+		t.Errorf("expected cursor to move on, got %+v", c)
+	}
+}
+
+func TestAuditTrailSince(t *testing.T) {
+	now := time.Date(2026, 1, 8, 12, 0, 0, 0, time.UTC)
+	if got, _ := auditTrailSince("2026-01-01T00:00:00Z", now); got != "2026-01-01T00:00:00Z" {
+		t.Errorf("timestamp should pass through, got %s", got)
+	}
+	if got, _ := auditTrailSince("7d", now); got != "2026-01-01T12:00:00Z" {
+		t.Errorf("unexpected since for 7d: %s", got)
+	}
+	if _, err := auditTrailSince("yesterday", now); err == nil {
+		t.Error("expected error for invalid --since")
+	}
+}
+
+func TestAuditTrailList_StateFile(t *testing.T) {
+	var sinces []string
+	var auth []string
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/organization/audit-trail" {
+			w.WriteHeader(404)
+			return
+		}
+		auth = append(auth, r.Header.Get("Authorization"))
+		q := r.URL.Query()
+		if q.Get("page[number]") == "1" {
+			sinces = append(sinces, q.Get("since"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if q.Get("page[number]") == "1" {
+			fmt.Fprintf(w, `{"data":[%s],"pagination":{"current_page":1,"next_page":2,"total_pages":2}}`,
+				testAuditEvent("ae-1", "2026-01-01T10:00:00Z", "create").Raw)
+			return
+		}
+		fmt.Fprintf(w, `{"data":[%s],"pagination":{"current_page":2,"next_page":null,"total_pages":2}}`,
+			testAuditEvent("ae-2", "2026-01-01T10:05:00Z", "update").Raw)
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "user-token")
+	t.Setenv("TFC_AUDIT_TOKEN", "org-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	dir := t.TempDir()
+	state := filepath.Join(dir, "audit.state")
+	out := filepath.Join(dir, "events.ndjson")
+	defer func() { flagOutputFile = "" }()
+
+	for i := 0; i < 2; i++ {
+		rootCmd.SetArgs([]string{"audit-trail", "list", "--state-file", state, "--ndjson", "-o", out})
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatalf("run %d: unexpected error: %v", i, err)
+		}
+		data, _ := os.ReadFile(out)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if i == 0 && (len(lines) != 2 || !strings.Contains(lines[1], `"ae-2"`)) {
+			t.Errorf("expected both events on the first run, got %q", data)
+		}
+		if i == 1 && strings.TrimSpace(string(data)) != "" {
+			t.Errorf("expected no events on the second run, got %q", data)
+		}
+	}
+
+	if len(sinces) != 2 || sinces[0] != "" || sinces[1] != "2026-01-01T10:05:00Z" {
+		t.Errorf("unexpected since parameters: %v", sinces)
+	}
+	if auth[0] != "Bearer org-token" {
+		t.Errorf("expected the audit token to be used, got %q", auth[0])
+	}
+}
+
+func TestAuditTrailList_StateFileNotSavedOnWriteError(t *testing.T) {
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":[%s],"pagination":{"current_page":1,"next_page":null,"total_pages":1}}`,
+			testAuditEvent("ae-1", "2026-01-01T10:00:00Z", "create").Raw)
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "user-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	dir := t.TempDir()
+	state := filepath.Join(dir, "audit.state")
+	// The parent of the output path is a file, so it cannot be created.
+	if err := os.WriteFile(filepath.Join(dir, "file"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "file", "events.ndjson")
+	defer func() { flagOutputFile = "" }()
+
+	rootCmd.SetArgs([]string{"audit-trail", "list", "--state-file", state, "--ndjson", "-o", out})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected an error writing the output file")
+	}
+	if _, err := os.Stat(state); !os.IsNotExist(err) {
+		t.Errorf("expected no state file after a failed write, got %v", err)
+	}
+}
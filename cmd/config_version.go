@@ -1,6 +1,17 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
 
 var configVersionCmd = &cobra.Command{
 	Use:     "config-version",
@@ -28,18 +39,48 @@ var configVersionShowCmd = &cobra.Command{
 var configVersionCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new configuration version",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("config-version create")
-	},
+	Long: `Create a new configuration version.
+
+The configuration version is left pending with an upload URL; upload a
+tarball to it with "tfc cv upload --config-version <id> <dir-or-tarball>".`,
+	RunE: runConfigVersionCreate,
 }
 
 var configVersionUploadCmd = &cobra.Command{
-	Use:   "upload [id] [file]",
-	Short: "Upload a tarball to a configuration version",
-	Args:  cobra.ExactArgs(2),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("config-version upload")
-	},
+	Use:   "upload [dir-or-tarball]",
+	Short: "Upload configuration to a workspace",
+	Long: `Upload configuration to a workspace.
+
+A directory is packed into a gzipped tarball, leaving out .git/, .terraform/
+and anything matched by its .terraformignore; a file is uploaded as an
+existing tarball. The upload goes to a new configuration version on
+--workspace, or to a pending one with --config-version.
+
+Waits until the configuration version is uploaded and, when runs are
+auto-queued, prints the run it queued.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigVersionUpload,
+}
+
+var configVersionDownloadCmd = &cobra.Command{
+	Use:   "download [id]",
+	Short: "Download a configuration version's archive",
+	Long: `Download a configuration version's archive.
+
+Writes the tarball to <id>.tar.gz, or to the file given with -o. With --run,
+downloads the configuration version the run used.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigVersionDownload,
+}
+
+var configVersionLsCmd = &cobra.Command{
+	Use:   "ls [id]",
+	Short: "List the files in a configuration version's archive",
+	Long: `List the files in a configuration version's archive with their sizes,
+without extracting it. With --run, lists the configuration version the run
+used.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigVersionLs,
 }
 
 func init() {
@@ -48,15 +89,280 @@ func init() {
 	configVersionListCmd.Flags().Int("page-size", 20, "Results per page")
 
 	// Create flags
-	configVersionCreateCmd.Flags().String("workspace", "", "Workspace ID (required)")
+	configVersionCreateCmd.Flags().String("workspace", "", "Workspace name or ID (required)")
 	configVersionCreateCmd.Flags().Bool("auto-queue-runs", true, "Auto-queue runs on upload")
 	configVersionCreateCmd.Flags().Bool("speculative", false, "Speculative plan only")
 
+	// Upload flags
+	configVersionUploadCmd.Flags().String("workspace", "", "Workspace name or ID")
+	configVersionUploadCmd.Flags().String("config-version", "", "Upload to an existing pending configuration version")
+	configVersionUploadCmd.Flags().Bool("auto-queue-runs", true, "Auto-queue runs on upload")
+	configVersionUploadCmd.Flags().Bool("speculative", false, "Speculative plan only")
+	configVersionUploadCmd.Flags().Duration("timeout", 2*time.Minute, "How long to wait for the upload to be processed")
+
+	configVersionDownloadCmd.Flags().String("run", "", "Use the configuration version of this run")
+	configVersionLsCmd.Flags().String("run", "", "Use the configuration version of this run")
+
 	configVersionCmd.AddCommand(
 		configVersionListCmd,
 		configVersionShowCmd,
 		configVersionCreateCmd,
 		configVersionUploadCmd,
+		configVersionDownloadCmd,
+		configVersionLsCmd,
 	)
 	rootCmd.AddCommand(configVersionCmd)
 }
+
+type configVersionAttrs struct {
+	Status           string            `json:"status"`
+	Source           string            `json:"source"`
+	AutoQueueRuns    bool              `json:"auto-queue-runs"`
+	Speculative      bool              `json:"speculative"`
+	UploadURL        string            `json:"upload-url,omitempty"`
+	Error            string            `json:"error"`
+	ErrorMessage     string            `json:"error-message"`
+	StatusTimestamps map[string]string `json:"status-timestamps"`
+}
+
+// createConfigVersion creates a pending configuration version on a workspace.
+func createConfigVersion(client *api.Client, wsID string, autoQueueRuns, speculative bool) (string, configVersionAttrs, error) {
+	var a configVersionAttrs
+	body, err := jsonapi.WrapForCreate("configuration-versions", map[string]interface{}{
+		"auto-queue-runs": autoQueueRuns,
+		"speculative":     speculative,
+	})
+	if err != nil {
+		return "", a, err
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post("/workspaces/"+wsID+"/configuration-versions", body, &doc); err != nil {
+		return "", a, err
+	}
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return "", a, err
+	}
+	jsonapi.UnmarshalAttributes(res, &a)
+	return res.ID, a, nil
+}
+
+// getConfigVersion fetches a configuration version with its workspace ID.
+func getConfigVersion(client *api.Client, cvID string) (configVersionAttrs, string, error) {
+	var a configVersionAttrs
+	var doc jsonapi.Document
+	if err := client.Get("/configuration-versions/"+cvID, &doc); err != nil {
+		return a, "", err
+	}
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return a, "", err
+	}
+	jsonapi.UnmarshalAttributes(res, &a)
+	return a, extractRelationshipID(res, "workspace"), nil
+}
+
+// packConfiguration returns the tarball to upload for path: a directory is
+// archived honoring .terraformignore, a file is read as-is.
+func packConfiguration(path string) (*bytes.Buffer, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewBuffer(data), nil
+	}
+
+	rules, err := loadTerraformIgnore(path)
+	if err != nil {
+		return nil, fmt.Errorf("read .terraformignore: %w", err)
+	}
+	return createArchive(path, rules.excluded)
+}
+
+// waitConfigVersionUploaded polls a configuration version until it leaves
+// the pending state, and fails if processing errored.
+func waitConfigVersionUploaded(client *api.Client, cvID string, timeout time.Duration) (configVersionAttrs, error) {
+	var a configVersionAttrs
+	err := waitFor(timeout, func() (bool, error) {
+		var err error
+		a, _, err = getConfigVersion(client, cvID)
+		return a.Status != "pending", err
+	})
+	if err != nil {
+		return a, fmt.Errorf("configuration version %s: %w", cvID, err)
+	}
+	if a.Status == "errored" {
+		return a, fmt.Errorf("configuration version %s errored: %s", cvID, defaultStr(a.ErrorMessage, defaultStr(a.Error, "no details")))
+	}
+	return a, nil
+}
+
+// findConfigVersionRun waits for the run queued from a configuration version
+// to appear in the workspace's recent runs. It returns "" if none appears
+// before the timeout.
+func findConfigVersionRun(client *api.Client, wsID, cvID string, timeout time.Duration) (string, runAttrs, error) {
+	var runID string
+	var a runAttrs
+	var apiErr error
+	waitFor(timeout, func() (bool, error) {
+		var doc jsonapi.Document
+		if apiErr = client.Get("/workspaces/"+wsID+"/runs?page[size]=20", &doc); apiErr != nil {
+			return false, apiErr
+		}
+		runs, err := jsonapi.ParseList(&doc)
+		if err != nil {
+			apiErr = err
+			return false, err
+		}
+		for _, r := range runs {
+			if extractRelationshipID(&r, "configuration-version") == cvID {
+				runID = r.ID
+				jsonapi.UnmarshalAttributes(&r, &a)
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	return runID, a, apiErr
+}
+
+func runConfigVersionCreate(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	autoQueueRuns, _ := cmd.Flags().GetBool("auto-queue-runs")
+	speculative, _ := cmd.Flags().GetBool("speculative")
+
+	if workspace == "" {
+		return output.NewUsageError("--workspace is required")
+	}
+
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	cvID, a, err := createConfigVersion(client, wsID, autoQueueRuns, speculative)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Configuration version %s created\n", cvID)
+	return renderConfigVersion(cvID, wsID, a, "", nil)
+}
+
+func runConfigVersionUpload(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	cvID, _ := cmd.Flags().GetString("config-version")
+	autoQueueRuns, _ := cmd.Flags().GetBool("auto-queue-runs")
+	speculative, _ := cmd.Flags().GetBool("speculative")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	path := args[0]
+
+	if (workspace == "") == (cvID == "") {
+		return output.NewUsageError("pass exactly one of --workspace or --config-version")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return output.NewUsageError(err.Error())
+	}
+
+	archive, err := packConfiguration(path)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var wsID string
+	var a configVersionAttrs
+	if cvID == "" {
+		if wsID, err = resolveWorkspaceID(workspace); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		if cvID, a, err = createConfigVersion(client, wsID, autoQueueRuns, speculative); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	} else {
+		if a, wsID, err = getConfigVersion(client, cvID); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		if a.Status != "pending" {
+			return output.NewUsageError(fmt.Sprintf("configuration version %s is %s, not pending", cvID, a.Status))
+		}
+	}
+	if a.UploadURL == "" {
+		return output.NewAPIError(fmt.Sprintf("configuration version %s has no upload URL", cvID))
+	}
+
+	stderr := cmd.ErrOrStderr()
+	fmt.Fprintf(stderr, "Uploading %s (%d bytes) to configuration version %s\n", path, archive.Len(), cvID)
+	if err := uploadArchive(a.UploadURL, archive); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if a, err = waitConfigVersionUploaded(client, cvID, timeout); err != nil {
+		if errors.Is(err, errWaitTimeout) {
+			return output.NewTimeoutError(err.Error())
+		}
+		return output.NewAPIError(err.Error())
+	}
+
+	var runID string
+	var ra runAttrs
+	if a.AutoQueueRuns && wsID != "" {
+		if runID, ra, err = findConfigVersionRun(client, wsID, cvID, timeout); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		if runID == "" {
+			fmt.Fprintf(stderr, "No run queued for configuration version %s yet\n", cvID)
+		} else {
+			fmt.Fprintf(stderr, "Run %s queued (%s)\n", runID, ra.Status)
+		}
+	}
+
+	return renderConfigVersion(cvID, wsID, a, runID, &ra)
+}
+
+func renderConfigVersion(cvID, wsID string, a configVersionAttrs, runID string, ra *runAttrs) error {
+	opts := GetOutputOptions()
+
+	type cvDetail struct {
+		ID          string             `json:"id"`
+		WorkspaceID string             `json:"workspace_id,omitempty"`
+		RunID       string             `json:"run_id,omitempty"`
+		Attrs       configVersionAttrs `json:"attributes"`
+	}
+	data := cvDetail{ID: cvID, WorkspaceID: wsID, RunID: runID, Attrs: a}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", cvID},
+			{"Workspace", defaultStr(wsID, "-")},
+			{"Status", a.Status},
+			{"Source", defaultStr(a.Source, "-")},
+			{"Auto Queue Runs", boolStr(a.AutoQueueRuns)},
+			{"Speculative", boolStr(a.Speculative)},
+		},
+	}
+	if a.Status == "pending" && a.UploadURL != "" {
+		td.Rows = append(td.Rows, []string{"Upload URL", a.UploadURL})
+	}
+	if runID != "" && ra != nil {
+		td.Rows = append(td.Rows, []string{"Run", runID}, []string{"Run Status", ra.Status})
+	}
+
+	return output.RenderTable(td, data, opts)
+}
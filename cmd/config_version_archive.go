@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// configVersionArg returns the configuration version to operate on: the
+// argument, or the one used by --run.
+func configVersionArg(cmd *cobra.Command, client *api.Client, args []string) (string, error) {
+	runID, _ := cmd.Flags().GetString("run")
+	if (len(args) == 1) == (runID != "") {
+		return "", output.NewUsageError("pass a configuration version ID or --run, not both")
+	}
+	if runID == "" {
+		return args[0], nil
+	}
+
+	var doc jsonapi.Document
+	if err := client.Get("/runs/"+runID, &doc); err != nil {
+		return "", output.NewAPIError(err.Error())
+	}
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return "", output.NewAPIError(err.Error())
+	}
+	cvID := extractRelationshipID(res, "configuration-version")
+	if cvID == "" {
+		return "", output.NewNotFoundError(fmt.Sprintf("run %s has no configuration version", runID))
+	}
+	return cvID, nil
+}
+
+// archiveEntry is one file in a configuration version archive.
+type archiveEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+	Mode string `json:"mode"`
+	Link string `json:"link,omitempty"`
+}
+
+// listArchive reads the entries of a gzipped tarball.
+func listArchive(r io.Reader) ([]archiveEntry, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("read archive: %w", err)
+	}
+	defer gz.Close()
+
+	var entries []archiveEntry
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		typ := "file"
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			typ = "dir"
+		case tar.TypeSymlink:
+			typ = "symlink"
+		case tar.TypeLink:
+			typ = "link"
+		}
+		entries = append(entries, archiveEntry{
+			Path: hdr.Name,
+			Type: typ,
+			Size: hdr.Size,
+			Mode: hdr.FileInfo().Mode().String(),
+			Link: hdr.Linkname,
+		})
+	}
+}
+
+func runConfigVersionDownload(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	cvID, err := configVersionArg(cmd, client, args)
+	if err != nil {
+		return err
+	}
+
+	body, err := client.GetRaw("/configuration-versions/" + cvID + "/download")
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	defer body.Close()
+
+	opts := GetOutputOptions()
+	if opts.OutputFile == "" {
+		opts.OutputFile = cvID + ".tar.gz"
+	}
+	return output.RenderStream(body, opts)
+}
+
+func runConfigVersionLs(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	cvID, err := configVersionArg(cmd, client, args)
+	if err != nil {
+		return err
+	}
+
+	body, err := client.GetRaw("/configuration-versions/" + cvID + "/download")
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	defer body.Close()
+
+	entries, err := listArchive(body)
+	if err != nil {
+		return output.NewAPIError(fmt.Sprintf("configuration version %s: %v", cvID, err))
+	}
+
+	opts := GetOutputOptions()
+
+	td := output.TableData{
+		Headers: []string{"MODE", "SIZE", "PATH"},
+	}
+	var total int64
+	files := 0
+	for _, e := range entries {
+		size := "-"
+		if e.Type == "file" {
+			size = fmt.Sprintf("%d", e.Size)
+			total += e.Size
+			files++
+		}
+		path := e.Path
+		if e.Link != "" {
+			path += " -> " + e.Link
+		}
+		td.Rows = append(td.Rows, []string{e.Mode, size, path})
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Configuration version %s: %d files, %d bytes\n", cvID, files, total)
+	return output.RenderTable(td, entries, opts)
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestConfigVersionUpload(t *testing.T) {
+	oldInterval := pollInterval
+	pollInterval = time.Millisecond
+	defer func() { pollInterval = oldInterval }()
+
+	var created map[string]interface{}
+	var uploaded []byte
+	polls := 0
+
+	var tsURL string
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v2/workspaces/ws-abc/configuration-versions":
+			var body struct {
+				Data struct {
+					Attributes map[string]interface{} `json:"attributes"`
+				} `json:"data"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			created = body.Data.Attributes
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "cv-1",
+					"type": "configuration-versions",
+					"attributes": map[string]interface{}{
+						"status": "pending", "auto-queue-runs": true, "speculative": true,
+						"upload-url": tsURL + "/upload/secret",
+					},
+				},
+			})
+		case r.Method == "PUT" && r.URL.Path == "/upload/secret":
+			uploaded, _ = io.ReadAll(r.Body)
+		case r.Method == "GET" && r.URL.Path == "/api/v2/configuration-versions/cv-1":
+			polls++
+			status := "pending"
+			if polls > 1 {
+				status = "uploaded"
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":         "cv-1",
+					"type":       "configuration-versions",
+					"attributes": map[string]interface{}{"status": status, "auto-queue-runs": true, "speculative": true},
+				},
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/v2/workspaces/ws-abc/runs":
+			w.Write([]byte(`{"data":[
+				{"id":"run-old","type":"runs","attributes":{"status":"applied"},
+				 "relationships":{"configuration-version":{"data":{"id":"cv-0","type":"configuration-versions"}}}},
+				{"id":"run-new","type":"runs","attributes":{"status":"pending"},
+				 "relationships":{"configuration-version":{"data":{"id":"cv-1","type":"configuration-versions"}}}}
+			]}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+	tsURL = ts.URL
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".terraform", "providers"), 0o755)
+	os.MkdirAll(filepath.Join(dir, ".git"), 0o755)
+	os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "null_resource" "a" {}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "terraform.tfstate"), []byte("{}"), 0o644)
+	os.WriteFile(filepath.Join(dir, ".terraformignore"), []byte("*.tfstate\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, ".terraform", "providers", "p"), []byte("bin"), 0o644)
+
+	rootCmd.SetArgs([]string{"cv", "upload", dir, "--workspace", "ws-abc", "--speculative", "--json"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if created["speculative"] != true || created["auto-queue-runs"] != true {
+		t.Errorf("unexpected configuration version attributes: %v", created)
+	}
+	got := archiveEntries(t, uploaded)
+	if len(got) != 2 || got[0] != ".terraformignore" || got[1] != "main.tf" {
+		t.Errorf("unexpected uploaded archive entries: %v", got)
+	}
+	if polls < 2 {
+		t.Errorf("expected configuration version to be polled until uploaded, polled %d times", polls)
+	}
+}
+
+func TestConfigVersionUpload_RequiresOneTarget(t *testing.T) {
+	rootCmd.SetArgs([]string{"cv", "upload", t.TempDir(), "--workspace", "", "--config-version", ""})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected usage error without --workspace or --config-version")
+	}
+}
+
+func TestConfigVersionArchive_ByRun(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "modules"), 0o755)
+	os.WriteFile(filepath.Join(dir, "main.tf"), []byte("terraform {}\n"), 0o644)
+	os.WriteFile(filepath.Join(dir, "modules", "vpc.tf"), []byte("# vpc\n"), 0o644)
+	archive, err := createArchive(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := archive.Bytes()
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/runs/run-1":
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			w.Write([]byte(`{"data":{"id":"run-1","type":"runs","attributes":{},
+				"relationships":{"configuration-version":{"data":{"id":"cv-1","type":"configuration-versions"}}}}}`))
+		case "/api/v2/configuration-versions/cv-1/download":
+			http.Redirect(w, r, "/archivist/cv-1", http.StatusFound)
+		case "/archivist/cv-1":
+			w.Write(data)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	entries, err := listArchive(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[0].Path != "main.tf" || entries[0].Size != 13 || entries[1].Type != "dir" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+
+	out := filepath.Join(t.TempDir(), "cv.tar.gz")
+	defer func() { flagOutputFile = "" }()
+	rootCmd.SetArgs([]string{"cv", "download", "--run", "run-1", "-o", out})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("downloaded archive does not match")
+	}
+}
+
+func TestConfigVersionArg_RequiresOne(t *testing.T) {
+	configVersionLsCmd.Flags().Set("run", "run-1")
+	defer configVersionLsCmd.Flags().Set("run", "")
+	if _, err := configVersionArg(configVersionLsCmd, nil, []string{"cv-1"}); err == nil {
+		t.Error("expected error for both an ID and --run")
+	}
+}
@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
 	"gitea.roboalch.com/roboalchemist/tfc/pkg/auth"
@@ -24,6 +26,20 @@ func newClient() (*api.Client, error) {
 	return client, nil
 }
 
+// newAuditClient creates a client for the audit trail API, authenticated with
+// the organization token from TFC_AUDIT_TOKEN when set.
+func newAuditClient() (*api.Client, error) {
+	token, err := auth.GetAuditToken()
+	if err != nil {
+		return nil, output.NewAuthError(err.Error())
+	}
+	client := api.NewClient(auth.GetAddress(), token)
+	if flagDebug {
+		client.SetDebug(DebugLog)
+	}
+	return client, nil
+}
+
 // requireOrg returns the organization name from --org flag or TFC_ORG env var.
 func requireOrg() (string, error) {
 	if flagOrg == "" {
@@ -86,3 +102,60 @@ func statusColor(s string) string {
 func joinTags(tags []string) string {
 	return strings.Join(tags, ", ")
 }
+
+// relationshipData builds a JSON:API relationship body listing resources of one type.
+func relationshipData(resourceType string, ids []string) map[string]interface{} {
+	data := make([]map[string]string, 0, len(ids))
+	for _, id := range ids {
+		data = append(data, map[string]string{"type": resourceType, "id": id})
+	}
+	return map[string]interface{}{"data": data}
+}
+
+// containsStr reports whether list contains s.
+func containsStr(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDuration parses a Go duration, also accepting whole days such as "30d".
+func parseDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// pollInterval is how often waitFor polls; tests shorten it.
+var pollInterval = 2 * time.Second
+
+// errWaitTimeout is returned (wrapped) by waitFor when the timeout passes.
+var errWaitTimeout = errors.New("timed out")
+
+// waitFor calls check every pollInterval until it reports done, returns an
+// error, or timeout passes.
+func waitFor(timeout time.Duration, check func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w after %s", errWaitTimeout, timeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
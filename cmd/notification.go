@@ -1,6 +1,13 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
 
 var notificationCmd = &cobra.Command{
 	Use:     "notification",
@@ -11,62 +18,85 @@ var notificationCmd = &cobra.Command{
 var notificationListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List notification configurations for a workspace",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("notification list")
-	},
+	RunE:  runNotificationList,
 }
 
 var notificationShowCmd = &cobra.Command{
 	Use:   "show [id]",
 	Short: "Show notification configuration details",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("notification show")
-	},
+	RunE:  runNotificationShow,
 }
 
 var notificationCreateCmd = &cobra.Command{
 	Use:   "create [name]",
 	Short: "Create a notification configuration",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("notification create")
-	},
+	RunE:  runNotificationCreate,
 }
 
 var notificationUpdateCmd = &cobra.Command{
 	Use:   "update [id]",
 	Short: "Update a notification configuration",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("notification update")
-	},
+	RunE:  runNotificationUpdate,
 }
 
 var notificationDeleteCmd = &cobra.Command{
 	Use:   "delete [id]",
 	Short: "Delete a notification configuration",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("notification delete")
-	},
+	RunE:  runNotificationDelete,
+}
+
+var notificationVerifyCmd = &cobra.Command{
+	Use:   "verify [id]",
+	Short: "Send a test notification and show the delivery response",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runNotificationVerify,
 }
 
+// notificationTriggers are the trigger names accepted by the API.
+var notificationTriggers = []string{
+	"run:created",
+	"run:planning",
+	"run:needs_attention",
+	"run:applying",
+	"run:completed",
+	"run:errored",
+	"assessment:drifted",
+	"assessment:failed",
+	"assessment:check_failure",
+	"workspace:auto_destroy_reminder",
+	"workspace:auto_destroy_run_results",
+}
+
+// notificationDestinations are the supported destination types.
+var notificationDestinations = []string{"generic", "slack", "email", "microsoft-teams"}
+
 func init() {
+	triggerHelp := "Trigger events: " + strings.Join(notificationTriggers, ", ")
+
 	// List flags
-	notificationListCmd.Flags().String("workspace", "", "Workspace ID (required)")
+	notificationListCmd.Flags().String("workspace", "", "Workspace name or ID (required)")
 
 	// Create flags
-	notificationCreateCmd.Flags().String("workspace", "", "Workspace ID (required)")
+	notificationCreateCmd.Flags().String("workspace", "", "Workspace name or ID (required)")
 	notificationCreateCmd.Flags().String("destination-type", "", "Type: generic, slack, email, microsoft-teams")
 	notificationCreateCmd.Flags().String("url", "", "Webhook URL (for generic/slack/microsoft-teams)")
-	notificationCreateCmd.Flags().StringSlice("triggers", nil, "Trigger events")
+	notificationCreateCmd.Flags().String("token", "", "HMAC signing token (generic only)")
+	notificationCreateCmd.Flags().StringSlice("triggers", nil, triggerHelp)
+	notificationCreateCmd.Flags().StringSlice("email-user", nil, "Users to email: user IDs, usernames or emails (email only)")
+	notificationCreateCmd.Flags().StringSlice("email-address", nil, "Email addresses to notify (email only, Terraform Enterprise)")
 	notificationCreateCmd.Flags().Bool("enabled", true, "Enable notification")
 
 	// Update flags
 	notificationUpdateCmd.Flags().String("name", "", "New name")
 	notificationUpdateCmd.Flags().String("url", "", "Webhook URL")
-	notificationUpdateCmd.Flags().StringSlice("triggers", nil, "Trigger events")
+	notificationUpdateCmd.Flags().String("token", "", "HMAC signing token (generic only)")
+	notificationUpdateCmd.Flags().StringSlice("triggers", nil, triggerHelp)
+	notificationUpdateCmd.Flags().StringSlice("email-user", nil, "Users to email: user IDs, usernames or emails (email only)")
+	notificationUpdateCmd.Flags().StringSlice("email-address", nil, "Email addresses to notify (email only, Terraform Enterprise)")
 	notificationUpdateCmd.Flags().Bool("enabled", true, "Enable notification")
 
 	notificationCmd.AddCommand(
@@ -75,6 +105,358 @@ func init() {
 		notificationCreateCmd,
 		notificationUpdateCmd,
 		notificationDeleteCmd,
+		notificationVerifyCmd,
 	)
 	rootCmd.AddCommand(notificationCmd)
 }
+
+type notifDeliveryResponse struct {
+	URL        string              `json:"url"`
+	Body       string              `json:"body"`
+	Code       string              `json:"code"`
+	Headers    map[string][]string `json:"headers"`
+	SentAt     string              `json:"sent-at"`
+	Successful string              `json:"successful"`
+}
+
+type notifAttrs struct {
+	Name              string                  `json:"name"`
+	DestinationType   string                  `json:"destination-type"`
+	Enabled           bool                    `json:"enabled"`
+	URL               string                  `json:"url"`
+	Triggers          []string                `json:"triggers"`
+	EmailAddresses    []string                `json:"email-addresses"`
+	DeliveryResponses []notifDeliveryResponse `json:"delivery-responses"`
+	CreatedAt         string                  `json:"created-at"`
+	UpdatedAt         string                  `json:"updated-at"`
+}
+
+// validateTriggers checks each trigger against the known trigger names.
+func validateTriggers(triggers []string) error {
+	known := map[string]bool{}
+	for _, t := range notificationTriggers {
+		known[t] = true
+	}
+	for _, t := range triggers {
+		if !known[t] {
+			return output.NewUsageError(fmt.Sprintf("unknown trigger %q: must be one of %s", t, strings.Join(notificationTriggers, ", ")))
+		}
+	}
+	return nil
+}
+
+// resolveEmailUsers resolves --email-user values to user IDs.
+func resolveEmailUsers(users []string) ([]string, error) {
+	var ids []string
+	for _, u := range users {
+		id, err := resolveUserID(u)
+		if err != nil {
+			return nil, output.NewAPIError(err.Error())
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func runNotificationList(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	if workspace == "" {
+		return output.NewUsageError("--workspace is required")
+	}
+
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var resources []jsonapi.Resource
+	path := fmt.Sprintf("/workspaces/%s/notification-configurations", wsID)
+	if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+		resources = append(resources, page...)
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	opts := GetOutputOptions()
+
+	type notifJSON struct {
+		ID    string     `json:"id"`
+		Attrs notifAttrs `json:"attributes"`
+	}
+	var jsonData []notifJSON
+	td := output.TableData{
+		Headers: []string{"ID", "NAME", "TYPE", "ENABLED", "TRIGGERS", "URL"},
+	}
+
+	for _, r := range resources {
+		var a notifAttrs
+		jsonapi.UnmarshalAttributes(&r, &a)
+		td.Rows = append(td.Rows, []string{
+			r.ID, a.Name, a.DestinationType, boolStr(a.Enabled),
+			truncateStr(defaultStr(joinTags(a.Triggers), "-"), 50), truncateStr(defaultStr(a.URL, "-"), 40),
+		})
+		jsonData = append(jsonData, notifJSON{ID: r.ID, Attrs: a})
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
+
+func runNotificationShow(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var doc jsonapi.Document
+	if err := client.Get("/notification-configurations/"+args[0], &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderNotification(cmd, &doc, "")
+}
+
+func runNotificationCreate(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	destType, _ := cmd.Flags().GetString("destination-type")
+	url, _ := cmd.Flags().GetString("url")
+	token, _ := cmd.Flags().GetString("token")
+	triggers, _ := cmd.Flags().GetStringSlice("triggers")
+	emailUsers, _ := cmd.Flags().GetStringSlice("email-user")
+	emailAddresses, _ := cmd.Flags().GetStringSlice("email-address")
+	enabled, _ := cmd.Flags().GetBool("enabled")
+
+	if workspace == "" {
+		return output.NewUsageError("--workspace is required")
+	}
+	if !containsStr(notificationDestinations, destType) {
+		return output.NewUsageError(fmt.Sprintf("--destination-type must be one of %s", strings.Join(notificationDestinations, ", ")))
+	}
+	if destType == "email" {
+		if url != "" {
+			return output.NewUsageError("--url is not used with email destinations")
+		}
+		if len(emailUsers) == 0 && len(emailAddresses) == 0 {
+			return output.NewUsageError("email destinations require --email-user or --email-address")
+		}
+	} else {
+		if url == "" {
+			return output.NewUsageError(fmt.Sprintf("--url is required for %s destinations", destType))
+		}
+		if len(emailUsers) > 0 || len(emailAddresses) > 0 {
+			return output.NewUsageError("--email-user and --email-address are only used with email destinations")
+		}
+	}
+	if token != "" && destType != "generic" {
+		return output.NewUsageError("--token is only used with generic destinations")
+	}
+	if err := validateTriggers(triggers); err != nil {
+		return err
+	}
+
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	userIDs, err := resolveEmailUsers(emailUsers)
+	if err != nil {
+		return err
+	}
+
+	attrs := map[string]interface{}{
+		"name":             args[0],
+		"destination-type": destType,
+		"enabled":          enabled,
+		"triggers":         triggers,
+	}
+	if triggers == nil {
+		attrs["triggers"] = []string{}
+	}
+	if url != "" {
+		attrs["url"] = url
+	}
+	if token != "" {
+		attrs["token"] = token
+	}
+	if len(emailAddresses) > 0 {
+		attrs["email-addresses"] = emailAddresses
+	}
+
+	data := map[string]interface{}{
+		"type":       "notification-configurations",
+		"attributes": attrs,
+	}
+	if len(userIDs) > 0 {
+		data["relationships"] = map[string]interface{}{
+			"users": relationshipData("users", userIDs),
+		}
+	}
+	body := map[string]interface{}{"data": data}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post(fmt.Sprintf("/workspaces/%s/notification-configurations", wsID), body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderNotification(cmd, &doc, "created")
+}
+
+func runNotificationUpdate(cmd *cobra.Command, args []string) error {
+	notifID := args[0]
+
+	attrs := map[string]interface{}{}
+	for _, name := range []string{"name", "url", "token"} {
+		if cmd.Flags().Changed(name) {
+			v, _ := cmd.Flags().GetString(name)
+			attrs[name] = v
+		}
+	}
+	if cmd.Flags().Changed("enabled") {
+		v, _ := cmd.Flags().GetBool("enabled")
+		attrs["enabled"] = v
+	}
+	if cmd.Flags().Changed("triggers") {
+		v, _ := cmd.Flags().GetStringSlice("triggers")
+		if err := validateTriggers(v); err != nil {
+			return err
+		}
+		attrs["triggers"] = append([]string{}, v...)
+	}
+	if cmd.Flags().Changed("email-address") {
+		v, _ := cmd.Flags().GetStringSlice("email-address")
+		attrs["email-addresses"] = append([]string{}, v...)
+	}
+
+	data := map[string]interface{}{
+		"id":         notifID,
+		"type":       "notification-configurations",
+		"attributes": attrs,
+	}
+	if cmd.Flags().Changed("email-user") {
+		v, _ := cmd.Flags().GetStringSlice("email-user")
+		userIDs, err := resolveEmailUsers(v)
+		if err != nil {
+			return err
+		}
+		data["relationships"] = map[string]interface{}{
+			"users": relationshipData("users", userIDs),
+		}
+	}
+	if len(attrs) == 0 && data["relationships"] == nil {
+		return output.NewUsageError("nothing to update: pass at least one flag")
+	}
+	body := map[string]interface{}{"data": data}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var doc jsonapi.Document
+	if err := client.Patch("/notification-configurations/"+notifID, body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderNotification(cmd, &doc, "updated")
+}
+
+func runNotificationDelete(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	notifID := args[0]
+	if err := client.Delete("/notification-configurations/" + notifID); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Notification configuration %s deleted\n", notifID)
+	return nil
+}
+
+func runNotificationVerify(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	notifID := args[0]
+	var doc jsonapi.Document
+	if err := client.Post(fmt.Sprintf("/notification-configurations/%s/actions/verify", notifID), nil, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a notifAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	opts := GetOutputOptions()
+
+	td := output.TableData{
+		Headers: []string{"URL", "CODE", "SUCCESSFUL", "SENT", "BODY"},
+	}
+	for _, d := range a.DeliveryResponses {
+		td.Rows = append(td.Rows, []string{
+			truncateStr(d.URL, 40), d.Code, d.Successful, d.SentAt,
+			truncateStr(strings.TrimSpace(d.Body), 60),
+		})
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Verification sent for notification configuration %s\n", res.ID)
+	return output.RenderTable(td, a.DeliveryResponses, opts)
+}
+
+func renderNotification(cmd *cobra.Command, doc *jsonapi.Document, verb string) error {
+	res, err := jsonapi.ParseSingle(doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a notifAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+	userIDs := extractRelationshipIDs(res, "users")
+
+	opts := GetOutputOptions()
+
+	type notifDetail struct {
+		ID      string     `json:"id"`
+		UserIDs []string   `json:"user_ids"`
+		Attrs   notifAttrs `json:"attributes"`
+	}
+	data := notifDetail{ID: res.ID, UserIDs: userIDs, Attrs: a}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Name", a.Name},
+			{"Destination Type", a.DestinationType},
+			{"Enabled", boolStr(a.Enabled)},
+			{"URL", defaultStr(a.URL, "-")},
+			{"Triggers", defaultStr(joinTags(a.Triggers), "-")},
+			{"Email Users", defaultStr(joinTags(userIDs), "-")},
+			{"Email Addresses", defaultStr(joinTags(a.EmailAddresses), "-")},
+			{"Created", a.CreatedAt},
+			{"Updated", a.UpdatedAt},
+		},
+	}
+
+	if verb != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Notification configuration %s %s\n", res.ID, verb)
+	}
+	return output.RenderTable(td, data, opts)
+}
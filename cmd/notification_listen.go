@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// notificationSignatureHeader carries the hex HMAC-SHA512 of the request body.
+const notificationSignatureHeader = "X-TFE-Notification-Signature"
+
+var notificationListenCmd = &cobra.Command{
+	Use:   "listen",
+	Short: "Run a local receiver for generic webhook notifications",
+	Long: `Run a local receiver for generic webhook notifications.
+
+Starts an HTTP server that accepts notification payloads on any path, verifies
+the X-TFE-Notification-Signature HMAC-SHA512 against --token, and prints one
+row per notification using the normal output modes (table, --json, --template).
+
+With --exec, the command is run through "sh -c" for every notification with
+the event JSON on stdin and TFC_RUN_ID, TFC_RUN_STATUS, TFC_WORKSPACE_NAME and
+TFC_TRIGGER set in its environment.
+
+Point a generic notification configuration at this server (for example through
+a tunnel) and use "tfc notification verify" to send a test payload.`,
+	RunE: runNotificationListen,
+}
+
+func init() {
+	notificationListenCmd.Flags().Int("port", 8080, "Port to listen on")
+	notificationListenCmd.Flags().String("bind", "127.0.0.1", "Address to bind to")
+	notificationListenCmd.Flags().String("token", "", "HMAC token configured on the notification (unsigned requests are accepted if empty)")
+	notificationListenCmd.Flags().String("exec", "", "Command to run for each event")
+
+	notificationCmd.AddCommand(notificationListenCmd)
+}
+
+// notificationPayload is the body of a generic webhook notification.
+type notificationPayload struct {
+	PayloadVersion              int    `json:"payload_version"`
+	NotificationConfigurationID string `json:"notification_configuration_id"`
+	RunURL                      string `json:"run_url"`
+	RunID                       string `json:"run_id"`
+	RunMessage                  string `json:"run_message"`
+	RunCreatedAt                string `json:"run_created_at"`
+	RunCreatedBy                string `json:"run_created_by"`
+	WorkspaceID                 string `json:"workspace_id"`
+	WorkspaceName               string `json:"workspace_name"`
+	OrganizationName            string `json:"organization_name"`
+	Notifications               []struct {
+		Message      string `json:"message"`
+		Trigger      string `json:"trigger"`
+		RunStatus    string `json:"run_status"`
+		RunUpdatedAt string `json:"run_updated_at"`
+		RunUpdatedBy string `json:"run_updated_by"`
+	} `json:"notifications"`
+}
+
+// notificationEvent is one notification from a payload, flattened for output.
+type notificationEvent struct {
+	ReceivedAt       string `json:"received_at"`
+	Trigger          string `json:"trigger"`
+	Message          string `json:"message"`
+	RunID            string `json:"run_id"`
+	RunStatus        string `json:"run_status"`
+	RunURL           string `json:"run_url"`
+	RunMessage       string `json:"run_message"`
+	RunUpdatedBy     string `json:"run_updated_by"`
+	WorkspaceID      string `json:"workspace_id"`
+	WorkspaceName    string `json:"workspace_name"`
+	OrganizationName string `json:"organization_name"`
+	ConfigurationID  string `json:"notification_configuration_id"`
+}
+
+// verifyHMACSHA512 checks a hex HMAC-SHA512 signature of body.
+func verifyHMACSHA512(body []byte, token, signature string) bool {
+	mac := hmac.New(sha512.New, []byte(token))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// flattenNotification expands a payload into one event per notification.
+func flattenNotification(p notificationPayload, received time.Time) []notificationEvent {
+	var events []notificationEvent
+	for _, n := range p.Notifications {
+		events = append(events, notificationEvent{
+			ReceivedAt:       received.UTC().Format(time.RFC3339),
+			Trigger:          n.Trigger,
+			Message:          n.Message,
+			RunID:            p.RunID,
+			RunStatus:        n.RunStatus,
+			RunURL:           p.RunURL,
+			RunMessage:       p.RunMessage,
+			RunUpdatedBy:     n.RunUpdatedBy,
+			WorkspaceID:      p.WorkspaceID,
+			WorkspaceName:    p.WorkspaceName,
+			OrganizationName: p.OrganizationName,
+			ConfigurationID:  p.NotificationConfigurationID,
+		})
+	}
+	return events
+}
+
+// newNotificationHandler returns a handler that verifies and parses
+// notification payloads, passing each event to onEvent.
+func newNotificationHandler(token string, onEvent func(notificationEvent), logf func(string, ...interface{})) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "read body", http.StatusBadRequest)
+			return
+		}
+
+		if token != "" && !verifyHMACSHA512(body, token, r.Header.Get(notificationSignatureHeader)) {
+			logf("Rejected request from %s: invalid or missing %s", r.RemoteAddr, notificationSignatureHeader)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		var payload notificationPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			logf("Rejected request from %s: %v", r.RemoteAddr, err)
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+
+		for _, ev := range flattenNotification(payload, time.Now()) {
+			onEvent(ev)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func runNotificationListen(cmd *cobra.Command, args []string) error {
+	port, _ := cmd.Flags().GetInt("port")
+	bind, _ := cmd.Flags().GetString("bind")
+	token, _ := cmd.Flags().GetString("token")
+	execCmd, _ := cmd.Flags().GetString("exec")
+
+	if flagOutputFile != "" {
+		return output.NewUsageError("--output is not supported by listen; redirect stdout instead")
+	}
+
+	stderr := cmd.ErrOrStderr()
+	logf := func(format string, args ...interface{}) {
+		fmt.Fprintf(stderr, format+"\n", args...)
+	}
+	if token == "" {
+		logf("Warning: no --token set, signatures will not be verified")
+	}
+
+	opts := GetOutputOptions()
+	var mu sync.Mutex
+	first := true
+
+	onEvent := func(ev notificationEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		td := output.TableData{
+			Rows: [][]string{{
+				ev.ReceivedAt, ev.Trigger, defaultStr(ev.RunStatus, "-"), defaultStr(ev.WorkspaceName, "-"),
+				defaultStr(ev.RunID, "-"), truncateStr(ev.Message, 60),
+			}},
+		}
+		if first {
+			td.Headers = []string{"RECEIVED", "TRIGGER", "STATUS", "WORKSPACE", "RUN", "MESSAGE"}
+			first = false
+		}
+		if err := output.RenderTable(td, ev, opts); err != nil {
+			logf("render event: %v", err)
+		}
+
+		if execCmd != "" {
+			if err := runNotificationExec(execCmd, ev); err != nil {
+				logf("exec: %v", err)
+			}
+		}
+	}
+
+	addr := net.JoinHostPort(bind, strconv.Itoa(port))
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           newNotificationHandler(token, onEvent, logf),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	logf("Listening for notifications on http://%s", addr)
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return output.NewInternalError(fmt.Sprintf("listen: %v", err))
+		}
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+		logf("Stopped")
+	}
+	return nil
+}
+
+// runNotificationExec runs command through the shell with the event on stdin.
+func runNotificationExec(command string, ev notificationEvent) error {
+	raw, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	c := exec.Command("sh", "-c", command)
+	c.Stdin = bytes.NewReader(raw)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(),
+		"TFC_RUN_ID="+ev.RunID,
+		"TFC_RUN_STATUS="+ev.RunStatus,
+		"TFC_WORKSPACE_NAME="+ev.WorkspaceName,
+		"TFC_TRIGGER="+ev.Trigger,
+	)
+	return c.Run()
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testNotificationPayload = `{
+  "payload_version": 1,
+  "notification_configuration_id": "nc-abc",
+  "run_url": "https://app.terraform.io/app/acme/prod/runs/run-123",
+  "run_id": "run-123",
+  "run_message": "Deploy",
+  "workspace_id": "ws-123",
+  "workspace_name": "prod",
+  "organization_name": "acme",
+  "notifications": [
+    {"message": "Run Applying", "trigger": "run:applying", "run_status": "applying", "run_updated_by": "alice"},
+    {"message": "Applied", "trigger": "run:completed", "run_status": "applied"}
+  ]
+}`
+
+func signNotification(body, token string) string {
+	mac := hmac.New(sha512.New, []byte(token))
+	mac.Write([]byte(body))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestNotificationHandler_ValidSignature(t *testing.T) {
+	var events []notificationEvent
+	h := newNotificationHandler("secret", func(ev notificationEvent) {
+		events = append(events, ev)
+	}, t.Logf)
+
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(testNotificationPayload))
+	req.Header.Set(notificationSignatureHeader, signNotification(testNotificationPayload, "secret"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Trigger != "run:applying" || events[0].WorkspaceName != "prod" || events[0].RunID != "run-123" {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	if events[1].RunStatus != "applied" {
+		t.Errorf("unexpected second event: %+v", events[1])
+	}
+}
+
+func TestNotificationHandler_BadSignature(t *testing.T) {
+	called := false
+	h := newNotificationHandler("secret", func(ev notificationEvent) { called = true }, t.Logf)
+
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(testNotificationPayload))
+	req.Header.Set(notificationSignatureHeader, signNotification(testNotificationPayload, "wrong"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", rec.Code)
+	}
+	if called {
+		t.Error("event callback should not run for a bad signature")
+	}
+}
+
+func TestNotificationHandler_NoToken(t *testing.T) {
+	count := 0
+	h := newNotificationHandler("", func(ev notificationEvent) { count++ }, t.Logf)
+
+	req := httptest.NewRequest("POST", "/hook", bytes.NewBufferString(testNotificationPayload))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || count != 2 {
+		t.Errorf("expected unsigned payload to be accepted without a token, got %d with %d events", rec.Code, count)
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValidateTriggers(t *testing.T) {
+	if err := validateTriggers([]string{"run:created", "run:errored", "assessment:drifted"}); err != nil {
+		t.Errorf("unexpected error for valid triggers: %v", err)
+	}
+	if err := validateTriggers([]string{"run:created", "run:exploded"}); err == nil {
+		t.Error("expected error for unknown trigger")
+	}
+}
+
+func TestNotificationCreate_Generic(t *testing.T) {
+	var capturedBody map[string]interface{}
+	var capturedPath string
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.Method == "POST" {
+			capturedPath = r.URL.Path
+			json.NewDecoder(r.Body).Decode(&capturedBody)
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "nc-abc123",
+					"type": "notification-configurations",
+					"attributes": map[string]interface{}{
+						"name":             "ci-hook",
+						"destination-type": "generic",
+						"enabled":          true,
+					},
+				},
+			})
+			return
+		}
+		w.WriteHeader(404)
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"notification", "create", "ci-hook", "--workspace", "ws-abc",
+		"--destination-type", "generic", "--url", "https://example.com/hook",
+		"--token", "s3cret", "--triggers", "run:completed,run:errored", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedPath != "/api/v2/workspaces/ws-abc/notification-configurations" {
+		t.Errorf("unexpected path %s", capturedPath)
+	}
+	attrs := capturedBody["data"].(map[string]interface{})["attributes"].(map[string]interface{})
+	if attrs["token"] != "s3cret" || attrs["url"] != "https://example.com/hook" {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+	triggers := attrs["triggers"].([]interface{})
+	if len(triggers) != 2 || triggers[0] != "run:completed" {
+		t.Errorf("unexpected triggers: %v", triggers)
+	}
+}
+
+func TestNotificationCreate_EmailRejectsURL(t *testing.T) {
+	t.Setenv("TFC_TOKEN", "test-token")
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"notification", "create", "mail", "--workspace", "ws-abc",
+		"--destination-type", "email", "--url", "https://example.com", "--triggers", ""})
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected error when --url is given for an email destination")
+	}
+	if !strings.Contains(err.Error(), "--url") {
+		t.Errorf("expected --url usage error, got: %v", err)
+	}
+}
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
+
 	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
 	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
 	"github.com/spf13/cobra"
@@ -139,3 +143,80 @@ func runOrgShow(cmd *cobra.Command, args []string) error {
 
 	return output.RenderTable(td, data, opts)
 }
+
+// resolveUserID resolves a user ID, username or email to a user ID.
+// If the value starts with "user-", it is returned as-is. Otherwise, the
+// organization's memberships are searched for a matching username or email.
+func resolveUserID(user string) (string, error) {
+	if strings.HasPrefix(user, "user-") {
+		return user, nil
+	}
+	_, userID, err := findMembership(user)
+	if err == nil && userID == "" {
+		return "", fmt.Errorf("resolve user %q: invitation not yet accepted", user)
+	}
+	return userID, err
+}
+
+// resolveMembershipID resolves an organization membership ID, username or
+// email to an organization membership ID.
+// If the value starts with "ou-", it is returned as-is.
+func resolveMembershipID(user string) (string, error) {
+	if strings.HasPrefix(user, "ou-") {
+		return user, nil
+	}
+	membershipID, _, err := findMembership(user)
+	return membershipID, err
+}
+
+// findMembership searches the organization's memberships for a username or
+// email and returns the membership ID and user ID.
+func findMembership(user string) (membershipID, userID string, err error) {
+	client, err := newClient()
+	if err != nil {
+		return "", "", err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return "", "", err
+	}
+
+	var doc jsonapi.Document
+	path := fmt.Sprintf("/organizations/%s/organization-memberships?include=user&q=%s", org, url.QueryEscape(user))
+	if err := client.Get(path, &doc); err != nil {
+		return "", "", fmt.Errorf("resolve user %q: %w", user, err)
+	}
+
+	resources, err := jsonapi.ParseList(&doc)
+	if err != nil {
+		return "", "", fmt.Errorf("resolve user %q: %w", user, err)
+	}
+	for _, m := range resources {
+		var memberEmail struct {
+			Email string `json:"email"`
+		}
+		jsonapi.UnmarshalAttributes(&m, &memberEmail)
+		if strings.EqualFold(memberEmail.Email, user) {
+			return m.ID, extractRelationshipID(&m, "user"), nil
+		}
+	}
+	for _, inc := range doc.Included {
+		if inc.Type != "users" {
+			continue
+		}
+		var u struct {
+			Username string `json:"username"`
+			Email    string `json:"email"`
+		}
+		jsonapi.UnmarshalAttributes(&inc, &u)
+		if u.Username != user && !strings.EqualFold(u.Email, user) {
+			continue
+		}
+		for _, m := range resources {
+			if extractRelationshipID(&m, "user") == inc.ID {
+				return m.ID, inc.ID, nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("resolve user %q: no member of organization %s matches", user, org)
+}
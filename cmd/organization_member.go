@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var orgMembersCmd = &cobra.Command{
+	Use:   "members",
+	Short: "Manage organization members",
+}
+
+var orgMembersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List organization members with status, email and teams",
+	RunE:  runOrgMembersList,
+}
+
+var orgMembersRemoveCmd = &cobra.Command{
+	Use:   "remove [user...]",
+	Short: "Remove users from the organization",
+	Long: `Remove users from the organization.
+
+Users are given by username, email or organization membership ID (ou-...).
+Removing a pending invitation revokes it.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runOrgMembersRemove,
+}
+
+var orgMembersPendingCmd = &cobra.Command{
+	Use:   "pending",
+	Short: "Report pending invitations",
+	Long: `Report invitations that have not been accepted yet.
+
+Use --older-than to only show invitations sent more than a given time ago,
+e.g. --older-than 14d, to find stale invites to chase up or revoke.`,
+	RunE: runOrgMembersPending,
+}
+
+var orgInviteCmd = &cobra.Command{
+	Use:   "invite [email]",
+	Short: "Invite a user to the organization",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runOrgInvite,
+}
+
+func init() {
+	orgMembersListCmd.Flags().String("status", "", "Only members with this status: active or invited")
+	orgMembersListCmd.Flags().String("search", "", "Only members whose username or email matches")
+
+	orgMembersPendingCmd.Flags().String("older-than", "", "Only invitations older than this, e.g. 7d or 48h")
+
+	orgInviteCmd.Flags().StringSlice("team", nil, "Team to add the user to (name or ID, repeatable)")
+
+	orgMembersCmd.AddCommand(orgMembersListCmd, orgMembersRemoveCmd, orgMembersPendingCmd)
+	orgCmd.AddCommand(orgMembersCmd, orgInviteCmd)
+}
+
+// orgMember is an organization membership with its user and teams resolved.
+type orgMember struct {
+	ID        string   `json:"id"`
+	UserID    string   `json:"user_id"`
+	Username  string   `json:"username"`
+	Email     string   `json:"email"`
+	Status    string   `json:"status"`
+	CreatedAt string   `json:"created_at"`
+	Teams     []string `json:"teams"`
+}
+
+// fetchOrgMembers lists organization memberships with usernames and team names.
+func fetchOrgMembers(client *api.Client, org, status, search string) ([]orgMember, error) {
+	path := fmt.Sprintf("/organizations/%s/organization-memberships?include=user,teams&page[size]=100", org)
+	if status != "" {
+		path += "&filter[status]=" + url.QueryEscape(status)
+	}
+	if search != "" {
+		path += "&q=" + url.QueryEscape(search)
+	}
+
+	var members []orgMember
+	err := client.GetAllDocuments(path, func(doc *jsonapi.Document) error {
+		resources, err := jsonapi.ParseList(doc)
+		if err != nil {
+			return err
+		}
+		usernames := map[string]string{}
+		teamNames := map[string]string{}
+		for _, inc := range doc.Included {
+			var a struct {
+				Username string `json:"username"`
+				Name     string `json:"name"`
+			}
+			jsonapi.UnmarshalAttributes(&inc, &a)
+			switch inc.Type {
+			case "users":
+				usernames[inc.ID] = a.Username
+			case "teams":
+				teamNames[inc.ID] = a.Name
+			}
+		}
+		for _, r := range resources {
+			var a struct {
+				Email     string `json:"email"`
+				Status    string `json:"status"`
+				CreatedAt string `json:"created-at"`
+			}
+			jsonapi.UnmarshalAttributes(&r, &a)
+			userID := extractRelationshipID(&r, "user")
+			var teams []string
+			for _, id := range extractRelationshipIDs(&r, "teams") {
+				teams = append(teams, defaultStr(teamNames[id], id))
+			}
+			sort.Strings(teams)
+			members = append(members, orgMember{
+				ID:        r.ID,
+				UserID:    userID,
+				Username:  usernames[userID],
+				Email:     a.Email,
+				Status:    a.Status,
+				CreatedAt: a.CreatedAt,
+				Teams:     teams,
+			})
+		}
+		return nil
+	})
+	return members, err
+}
+
+// staleInvitations returns the invited members created more than olderThan
+// before now. Members without a parseable creation time are returned
+// separately since their age is unknown.
+func staleInvitations(members []orgMember, olderThan time.Duration, now time.Time) (stale, unknown []orgMember) {
+	for _, m := range members {
+		if m.Status != "invited" {
+			continue
+		}
+		created, err := time.Parse(time.RFC3339, m.CreatedAt)
+		if err != nil {
+			unknown = append(unknown, m)
+			continue
+		}
+		if now.Sub(created) >= olderThan {
+			stale = append(stale, m)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool { return stale[i].CreatedAt < stale[j].CreatedAt })
+	return stale, unknown
+}
+
+func runOrgMembersList(cmd *cobra.Command, args []string) error {
+	status, _ := cmd.Flags().GetString("status")
+	search, _ := cmd.Flags().GetString("search")
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	members, err := fetchOrgMembers(client, org, status, search)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	opts := GetOutputOptions()
+
+	td := output.TableData{
+		Headers: []string{"ID", "USERNAME", "EMAIL", "STATUS", "TEAMS"},
+	}
+	for _, m := range members {
+		td.Rows = append(td.Rows, []string{
+			m.ID, defaultStr(m.Username, "-"), m.Email, m.Status,
+			truncateStr(defaultStr(joinTags(m.Teams), "-"), 50),
+		})
+	}
+
+	return output.RenderTable(td, members, opts)
+}
+
+func runOrgMembersPending(cmd *cobra.Command, args []string) error {
+	olderThanStr, _ := cmd.Flags().GetString("older-than")
+	var olderThan time.Duration
+	if olderThanStr != "" {
+		d, err := parseDuration(olderThanStr)
+		if err != nil {
+			return output.NewUsageError(fmt.Sprintf("invalid --older-than: %v", err))
+		}
+		olderThan = d
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	members, err := fetchOrgMembers(client, org, "invited", "")
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	now := time.Now()
+	stale, unknown := staleInvitations(members, olderThan, now)
+	if olderThan == 0 {
+		stale = append(stale, unknown...)
+	} else if len(unknown) > 0 && len(unknown) == len(members) {
+		// Without any creation times the report would always be empty.
+		return output.NewAPIError(fmt.Sprintf("none of the %d invitation(s) has a creation time; cannot tell which are older than %s", len(unknown), olderThanStr))
+	} else if len(unknown) > 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Skipped %d invitation(s) without a creation time\n", len(unknown))
+	}
+
+	opts := GetOutputOptions()
+
+	td := output.TableData{
+		Headers: []string{"ID", "EMAIL", "INVITED", "AGE (DAYS)", "TEAMS"},
+	}
+	for _, m := range stale {
+		age := "-"
+		if created, err := time.Parse(time.RFC3339, m.CreatedAt); err == nil {
+			age = itoa(int(now.Sub(created).Hours() / 24))
+		}
+		td.Rows = append(td.Rows, []string{
+			m.ID, m.Email, defaultStr(shortDate(m.CreatedAt), "-"), age,
+			truncateStr(defaultStr(joinTags(m.Teams), "-"), 50),
+		})
+	}
+
+	return output.RenderTable(td, stale, opts)
+}
+
+func runOrgMembersRemove(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	for _, user := range args {
+		id, err := resolveMembershipID(user)
+		if err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		ids = append(ids, id)
+	}
+
+	for i, id := range ids {
+		if err := client.Delete("/organization-memberships/" + id); err != nil {
+			return output.NewAPIError(fmt.Sprintf("remove %s: %v", args[i], err))
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Removed %s (%s) from the organization\n", args[i], id)
+	}
+	return nil
+}
+
+func runOrgInvite(cmd *cobra.Command, args []string) error {
+	teams, _ := cmd.Flags().GetStringSlice("team")
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	var teamIDs []string
+	for _, team := range teams {
+		id, err := resolveTeamID(team)
+		if err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		teamIDs = append(teamIDs, id)
+	}
+
+	data := map[string]interface{}{
+		"type":       "organization-memberships",
+		"attributes": map[string]interface{}{"email": args[0]},
+	}
+	if len(teamIDs) > 0 {
+		data["relationships"] = map[string]interface{}{
+			"teams": relationshipData("teams", teamIDs),
+		}
+	}
+	body := map[string]interface{}{"data": data}
+
+	var doc jsonapi.Document
+	if err := client.Post("/organizations/"+org+"/organization-memberships", body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a struct {
+		Email  string `json:"email"`
+		Status string `json:"status"`
+	}
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	opts := GetOutputOptions()
+
+	member := orgMember{
+		ID:     res.ID,
+		UserID: extractRelationshipID(res, "user"),
+		Email:  a.Email,
+		Status: a.Status,
+		Teams:  teams,
+	}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", member.ID},
+			{"Email", member.Email},
+			{"Status", member.Status},
+			{"Teams", defaultStr(joinTags(member.Teams), "-")},
+		},
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Invited %s to %s\n", args[0], org)
+	return output.RenderTable(td, member, opts)
+}
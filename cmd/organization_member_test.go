@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStaleInvitations(t *testing.T) {
+	now := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	members := []orgMember{
+		{ID: "ou-active", Status: "active", CreatedAt: "2023-01-01T00:00:00Z"},
+		{ID: "ou-new", Status: "invited", CreatedAt: "2024-03-29T00:00:00Z"},
+		{ID: "ou-old", Status: "invited", CreatedAt: "2024-03-01T00:00:00Z"},
+		{ID: "ou-older", Status: "invited", CreatedAt: "2024-01-15T00:00:00Z"},
+		{ID: "ou-unknown", Status: "invited"},
+	}
+
+	stale, unknown := staleInvitations(members, 7*24*time.Hour, now)
+	if len(stale) != 2 || stale[0].ID != "ou-older" || stale[1].ID != "ou-old" {
+		t.Errorf("expected oldest stale invitations first, got %+v", stale)
+	}
+	if len(unknown) != 1 || unknown[0].ID != "ou-unknown" {
+		t.Errorf("expected invitation without timestamp reported as unknown, got %+v", unknown)
+	}
+
+	stale, _ = staleInvitations(members, 0, now)
+	if len(stale) != 3 {
+		t.Errorf("expected every dated invitation with no threshold, got %d", len(stale))
+	}
+}
+
+func TestOrgInvite_WithTeams(t *testing.T) {
+	var capturedBody map[string]interface{}
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/teams":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"id": "team-dev", "type": "teams", "attributes": map[string]interface{}{"name": "developers"}},
+				},
+			})
+		case r.Method == "POST" && r.URL.Path == "/api/v2/organizations/test-org/organization-memberships":
+			json.NewDecoder(r.Body).Decode(&capturedBody)
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":         "ou-new",
+					"type":       "organization-memberships",
+					"attributes": map[string]interface{}{"email": "new@example.com", "status": "invited"},
+				},
+			})
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"org", "invite", "--org", "test-org", "new@example.com", "--team", "developers", "--team", "team-ops", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := capturedBody["data"].(map[string]interface{})
+	if email := data["attributes"].(map[string]interface{})["email"]; email != "new@example.com" {
+		t.Errorf("expected email in body, got %v", email)
+	}
+	teams := data["relationships"].(map[string]interface{})["teams"].(map[string]interface{})["data"].([]interface{})
+	if len(teams) != 2 {
+		t.Fatalf("expected 2 teams, got %v", teams)
+	}
+	if teams[0].(map[string]interface{})["id"] != "team-dev" || teams[1].(map[string]interface{})["id"] != "team-ops" {
+		t.Errorf("expected resolved team ids, got %v", teams)
+	}
+}
+
+func TestOrgMembersPending_ReadsCreatedAt(t *testing.T) {
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path != "/api/v2/organizations/test-org/organization-memberships" {
+			w.WriteHeader(404)
+			return
+		}
+		if got := r.URL.Query().Get("filter[status]"); got != "invited" {
+			t.Errorf("expected invited filter, got %q", got)
+		}
+		w.Write([]byte(`{"data":[
+			{"id":"ou-old","type":"organization-memberships",
+			 "attributes":{"email":"old@example.com","status":"invited","created-at":"2020-01-01T00:00:00.000Z"}},
+			{"id":"ou-new","type":"organization-memberships",
+			 "attributes":{"email":"new@example.com","status":"invited","created-at":"` + time.Now().UTC().Format(time.RFC3339) + `"}}]}`))
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	out := filepath.Join(t.TempDir(), "pending.json")
+	defer func() { flagJSON = false; flagOutputFile = "" }()
+	rootCmd.SetArgs([]string{"org", "members", "pending", "--org", "test-org", "--older-than", "30d", "--json", "-o", out})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stale []orgMember
+	if err := json.Unmarshal(data, &stale); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if len(stale) != 1 || stale[0].ID != "ou-old" || stale[0].CreatedAt != "2020-01-01T00:00:00.000Z" {
+		t.Errorf("expected only ou-old with its creation time, got %+v", stale)
+	}
+}
+
+func TestOrgMembersPending_NoCreationTimes(t *testing.T) {
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		w.Write([]byte(`{"data":[{"id":"ou-1","type":"organization-memberships",
+			"attributes":{"email":"a@example.com","status":"invited"}}]}`))
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	rootCmd.SetArgs([]string{"org", "members", "pending", "--org", "test-org", "--older-than", "30d", "--json=false"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "creation time") {
+		t.Fatalf("expected an error about missing creation times, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var planLocalCmd = &cobra.Command{
+	Use:   "local [dir]",
+	Short: "Run a speculative plan of a local directory",
+	Long: `Run a speculative plan of a local directory on a workspace.
+
+Uploads the directory as a speculative configuration version (honoring
+.terraformignore), waits for the run it queues and follows the plan log to
+completion, like "terraform plan" with a remote backend.
+
+Exits like -detailed-exitcode: 0 when the plan has no changes, 1 when the
+plan fails, 2 when it has changes. With --json or --template the log goes to
+stderr and a plan summary is written instead.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPlanLocal,
+}
+
+func init() {
+	planLocalCmd.Flags().String("workspace", "", "Workspace name or ID (required)")
+	planLocalCmd.Flags().Duration("timeout", 30*time.Minute, "How long to wait for each of upload, run and plan")
+
+	planCmd.AddCommand(planLocalCmd)
+}
+
+// planDone reports whether a plan status is final.
+func planDone(status string) bool {
+	switch status {
+	case "finished", "errored", "canceled", "unreachable":
+		return true
+	}
+	return false
+}
+
+// followPlanLog writes a plan's log to w as it grows and returns the plan
+// once it reaches a final status.
+func followPlanLog(client *api.Client, planID string, w io.Writer, timeout time.Duration) (planAttrs, error) {
+	var a planAttrs
+	written := 0
+	err := waitFor(timeout, func() (bool, error) {
+		var doc jsonapi.Document
+		if err := client.Get("/plans/"+planID, &doc); err != nil {
+			return false, err
+		}
+		res, err := jsonapi.ParseSingle(&doc)
+		if err != nil {
+			return false, err
+		}
+		a = planAttrs{}
+		jsonapi.UnmarshalAttributes(res, &a)
+		if a.LogReadURL == "" {
+			return planDone(a.Status), nil
+		}
+
+		body, err := fetchLogURL(a.LogReadURL)
+		if err != nil {
+			return false, err
+		}
+		data, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return false, fmt.Errorf("fetch log: %w", err)
+		}
+		// Logs are framed by STX and ETX control characters.
+		data = bytes.Trim(data, "\x02\x03")
+		if len(data) > written {
+			if _, err := w.Write(data[written:]); err != nil {
+				return false, err
+			}
+			written = len(data)
+		}
+		return planDone(a.Status), nil
+	})
+	return a, err
+}
+
+func runPlanLocal(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	dir := args[0]
+
+	// Exit code 2 is reserved for "plan has changes", as with
+	// terraform plan -detailed-exitcode, so every other failure exits 1.
+	if workspace == "" {
+		return output.NewError(output.ErrTypeUsageError, "--workspace is required", 1)
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return output.NewError(output.ErrTypeUsageError, fmt.Sprintf("%s is not a directory", dir), 1)
+	}
+
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	archive, err := packConfiguration(dir)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	cvID, cv, err := createConfigVersion(client, wsID, true, true)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	if cv.UploadURL == "" {
+		return output.NewAPIError(fmt.Sprintf("configuration version %s has no upload URL", cvID))
+	}
+
+	stderr := cmd.ErrOrStderr()
+	fmt.Fprintf(stderr, "Uploading %s (%d bytes) to configuration version %s\n", dir, archive.Len(), cvID)
+	if err := uploadArchive(cv.UploadURL, archive); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	if _, err := waitConfigVersionUploaded(client, cvID, timeout); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	runID, _, err := findConfigVersionRun(client, wsID, cvID, timeout)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	if runID == "" {
+		return output.NewError(output.ErrTypeTimeout, fmt.Sprintf("no run was queued for configuration version %s within %s", cvID, timeout), 1)
+	}
+
+	var runDoc jsonapi.Document
+	if err := client.Get("/runs/"+runID, &runDoc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	runRes, err := jsonapi.ParseSingle(&runDoc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	planID := extractRelationshipID(runRes, "plan")
+	if planID == "" {
+		return output.NewAPIError(fmt.Sprintf("run %s has no plan", runID))
+	}
+	fmt.Fprintf(stderr, "Run %s, plan %s\n", runID, planID)
+
+	opts := GetOutputOptions()
+	summary := opts.Mode == output.ModeJSON || opts.Mode == output.ModeTemplate
+	logOut := cmd.OutOrStdout()
+	if summary {
+		logOut = stderr
+	}
+
+	a, err := followPlanLog(client, planID, logOut, timeout)
+	if err != nil {
+		return output.NewAPIError(fmt.Sprintf("plan %s: %v", planID, err))
+	}
+
+	if summary {
+		type planSummary struct {
+			RunID  string    `json:"run_id"`
+			PlanID string    `json:"plan_id"`
+			Attrs  planAttrs `json:"attributes"`
+		}
+		if err := output.RenderTable(output.TableData{}, planSummary{RunID: runID, PlanID: planID, Attrs: a}, opts); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case a.Status != "finished":
+		return output.NewAPIError(fmt.Sprintf("plan %s %s", planID, a.Status))
+	case a.HasChanges:
+		return output.NewError(output.ErrTypePlanChanges, fmt.Sprintf("plan %s has changes: %d to add, %d to change, %d to destroy",
+			planID, a.ResourceAdditions, a.ResourceChanges, a.ResourceDestructions), 2)
+	}
+	fmt.Fprintf(stderr, "Plan %s has no changes\n", planID)
+	return nil
+}
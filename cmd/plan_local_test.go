@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+)
+
+func TestPlanLocal_ChangesExitCode(t *testing.T) {
+	oldInterval := pollInterval
+	pollInterval = time.Millisecond
+	defer func() { pollInterval = oldInterval }()
+
+	planPolls := 0
+	var tsURL string
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v2/workspaces/ws-abc/configuration-versions":
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id": "cv-1", "type": "configuration-versions",
+					"attributes": map[string]interface{}{"status": "pending", "upload-url": tsURL + "/upload/secret"},
+				},
+			})
+		case r.Method == "PUT" && r.URL.Path == "/upload/secret":
+		case r.Method == "GET" && r.URL.Path == "/api/v2/configuration-versions/cv-1":
+			w.Write([]byte(`{"data":{"id":"cv-1","type":"configuration-versions","attributes":{"status":"uploaded"}}}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v2/workspaces/ws-abc/runs":
+			w.Write([]byte(`{"data":[{"id":"run-1","type":"runs","attributes":{"status":"planning"},
+				"relationships":{"configuration-version":{"data":{"id":"cv-1","type":"configuration-versions"}}}}]}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v2/runs/run-1":
+			w.Write([]byte(`{"data":{"id":"run-1","type":"runs","attributes":{"status":"planning"},
+				"relationships":{"plan":{"data":{"id":"plan-1","type":"plans"}}}}}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v2/plans/plan-1":
+			planPolls++
+			attrs := map[string]interface{}{"status": "running", "log-read-url": tsURL + "/log/1"}
+			if planPolls > 1 {
+				attrs = map[string]interface{}{"status": "finished", "log-read-url": tsURL + "/log/2",
+					"has-changes": true, "resource-additions": 1}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"id": "plan-1", "type": "plans", "attributes": attrs},
+			})
+		case r.URL.Path == "/log/1":
+			w.Write([]byte("\x02Terraform v1.9.0\n"))
+		case r.URL.Path == "/log/2":
+			w.Write([]byte("\x02Terraform v1.9.0\nPlan: 1 to add, 0 to change, 0 to destroy.\n\x03"))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+	tsURL = ts.URL
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "null_resource" "a" {}`), 0o644)
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	defer rootCmd.SetOut(nil)
+	rootCmd.SetArgs([]string{"plan", "local", dir, "--workspace", "ws-abc", "--json=false"})
+	err := rootCmd.Execute()
+
+	var se *output.StructuredError
+	if !errors.As(err, &se) || se.ExitCode != 2 || se.Type != output.ErrTypePlanChanges {
+		t.Fatalf("expected plan changes error with exit code 2, got %v", err)
+	}
+	if want := "Terraform v1.9.0\nPlan: 1 to add, 0 to change, 0 to destroy.\n"; out.String() != want {
+		t.Errorf("unexpected log output %q", out.String())
+	}
+}
+
+func TestPlanDone(t *testing.T) {
+	for status, want := range map[string]bool{
+		"running": false, "queued": false, "finished": true, "errored": true, "canceled": true,
+	} {
+		if got := planDone(status); got != want {
+			t.Errorf("planDone(%q) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestPlanLocal_FailuresExitOne(t *testing.T) {
+	oldInterval := pollInterval
+	pollInterval = time.Millisecond
+	defer func() { pollInterval = oldInterval }()
+
+	var tsURL string
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v2/workspaces/ws-abc/configuration-versions":
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id": "cv-1", "type": "configuration-versions",
+					"attributes": map[string]interface{}{"status": "pending", "upload-url": tsURL + "/upload/secret"},
+				},
+			})
+		case r.Method == "PUT" && r.URL.Path == "/upload/secret":
+		case r.Method == "GET" && r.URL.Path == "/api/v2/configuration-versions/cv-1":
+			w.Write([]byte(`{"data":{"id":"cv-1","type":"configuration-versions","attributes":{"status":"uploaded"}}}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v2/workspaces/ws-abc/runs":
+			w.Write([]byte(`{"data":[]}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+	tsURL = ts.URL
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "null_resource" "a" {}`), 0o644)
+
+	tests := []struct {
+		name    string
+		args    []string
+		errType string
+	}{
+		{"missing workspace", []string{dir, "--workspace", ""}, output.ErrTypeUsageError},
+		{"not a directory", []string{filepath.Join(dir, "main.tf"), "--workspace", "ws-abc"}, output.ErrTypeUsageError},
+		{"no run queued", []string{dir, "--workspace", "ws-abc", "--timeout", "20ms"}, output.ErrTypeTimeout},
+	}
+	defer planLocalCmd.Flags().Set("timeout", "30m")
+	for _, tt := range tests {
+		rootCmd.SetArgs(append([]string{"plan", "local", "--json=false"}, tt.args...))
+		err := rootCmd.Execute()
+
+		var se *output.StructuredError
+		if !errors.As(err, &se) || se.ExitCode != 1 || se.Type != tt.errType {
+			t.Errorf("%s: expected %s error with exit code 1, got %v", tt.name, tt.errType, err)
+		}
+	}
+}
@@ -1,31 +1,479 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
 
 var policyCmd = &cobra.Command{
 	Use:     "policy",
 	Aliases: []string{"pol"},
-	Short:   "View policies",
+	Short:   "Manage policies",
 }
 
 var policyListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List policies in an organization",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("policy list")
-	},
+	RunE:  runPolicyList,
 }
 
 var policyShowCmd = &cobra.Command{
-	Use:   "show [id]",
+	Use:   "show [policy]",
 	Short: "Show policy details",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("policy show")
-	},
+	RunE:  runPolicyShow,
+}
+
+var policyCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a policy and upload its code",
+	Long: `Create a policy and upload its code.
+
+Sentinel policies use --enforcement advisory, soft-mandatory or
+hard-mandatory. OPA policies use advisory or mandatory and need a --query,
+e.g. "data.terraform.policies.public_buckets.deny".`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPolicyCreate,
+}
+
+var policyUpdateCmd = &cobra.Command{
+	Use:   "update [policy]",
+	Short: "Update a policy, optionally uploading new code",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolicyUpdate,
+}
+
+var policyDeleteCmd = &cobra.Command{
+	Use:   "delete [policy]",
+	Short: "Delete a policy",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolicyDelete,
+}
+
+// policyKinds are the accepted values for --kind.
+var policyKinds = []string{"sentinel", "opa"}
+
+// policyEnforcementLevels lists the enforcement levels allowed for each kind.
+var policyEnforcementLevels = map[string][]string{
+	"sentinel": {"advisory", "soft-mandatory", "hard-mandatory"},
+	"opa":      {"advisory", "mandatory"},
 }
 
 func init() {
-	policyCmd.AddCommand(policyListCmd, policyShowCmd)
+	policyListCmd.Flags().String("kind", "", "Only policies of this kind: sentinel or opa")
+	policyListCmd.Flags().String("search", "", "Only policies whose name contains this")
+
+	policyShowCmd.Flags().Bool("code", false, "Print the policy code instead of its details")
+
+	policyCreateCmd.Flags().String("kind", "sentinel", "Policy kind: sentinel or opa")
+	policyCreateCmd.Flags().String("file", "", "Policy code file to upload (required)")
+	policyCreateCmd.Flags().String("description", "", "Description")
+	policyCreateCmd.Flags().String("enforcement", "advisory", "Enforcement level")
+	policyCreateCmd.Flags().String("query", "", "OPA query (required for opa)")
+	policyCreateCmd.Flags().StringSlice("policy-set", nil, "Policy set to add the policy to (name or ID, repeatable)")
+
+	policyUpdateCmd.Flags().String("file", "", "Policy code file to upload")
+	policyUpdateCmd.Flags().String("description", "", "Description")
+	policyUpdateCmd.Flags().String("enforcement", "", "Enforcement level")
+	policyUpdateCmd.Flags().String("query", "", "OPA query")
+
+	policyCmd.AddCommand(
+		policyListCmd,
+		policyShowCmd,
+		policyCreateCmd,
+		policyUpdateCmd,
+		policyDeleteCmd,
+	)
 	rootCmd.AddCommand(policyCmd)
 }
+
+type policyAttrs struct {
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	Kind             string `json:"kind"`
+	Query            string `json:"query,omitempty"`
+	EnforcementLevel string `json:"enforcement-level"`
+	Enforce          []struct {
+		Path string `json:"path"`
+		Mode string `json:"mode"`
+	} `json:"enforce,omitempty"`
+	PolicySetCount int    `json:"policy-set-count"`
+	UpdatedAt      string `json:"updated-at"`
+}
+
+// enforcement returns the enforcement level, falling back to the legacy
+// enforce list used by older Sentinel policies.
+func (a policyAttrs) enforcement() string {
+	if a.EnforcementLevel != "" {
+		return a.EnforcementLevel
+	}
+	if len(a.Enforce) > 0 {
+		return a.Enforce[0].Mode
+	}
+	return ""
+}
+
+// validatePolicyEnforcement checks that level is allowed for kind.
+func validatePolicyEnforcement(kind, level string) error {
+	levels, ok := policyEnforcementLevels[kind]
+	if !ok {
+		return output.NewUsageError(fmt.Sprintf("--kind must be one of %s", strings.Join(policyKinds, ", ")))
+	}
+	if !containsStr(levels, level) {
+		return output.NewUsageError(fmt.Sprintf("--enforcement for %s policies must be one of %s", kind, strings.Join(levels, ", ")))
+	}
+	return nil
+}
+
+// resolvePolicyID resolves a policy name or ID to a policy ID.
+// If the value starts with "pol-", it is returned as-is.
+func resolvePolicyID(client *api.Client, policy string) (string, error) {
+	if strings.HasPrefix(policy, "pol-") {
+		return policy, nil
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	path := fmt.Sprintf("/organizations/%s/policies?search[name]=%s&page[size]=100", org, url.QueryEscape(policy))
+	if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a policyAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			if a.Name == policy && id == "" {
+				id = r.ID
+			}
+		}
+	}); err != nil {
+		return "", fmt.Errorf("resolve policy %q: %w", policy, err)
+	}
+	if id == "" {
+		return "", fmt.Errorf("resolve policy %q: not found in organization %s", policy, org)
+	}
+	return id, nil
+}
+
+// uploadPolicyCode uploads the contents of file as the policy's code.
+func uploadPolicyCode(client *api.Client, policyID, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return output.NewUsageError(fmt.Sprintf("open policy file: %v", err))
+	}
+	defer f.Close()
+	if err := client.PutRaw("/policies/"+policyID+"/upload", f); err != nil {
+		return output.NewAPIError(fmt.Sprintf("upload policy code: %v", err))
+	}
+	return nil
+}
+
+func runPolicyList(cmd *cobra.Command, args []string) error {
+	kind, _ := cmd.Flags().GetString("kind")
+	search, _ := cmd.Flags().GetString("search")
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/organizations/%s/policies?page[size]=100", org)
+	if kind != "" {
+		path += "&filter[kind]=" + url.QueryEscape(kind)
+	}
+	if search != "" {
+		path += "&search[name]=" + url.QueryEscape(search)
+	}
+
+	opts := GetOutputOptions()
+
+	type policyJSON struct {
+		ID    string      `json:"id"`
+		Attrs policyAttrs `json:"attributes"`
+	}
+	var jsonData []policyJSON
+	td := output.TableData{
+		Headers: []string{"ID", "NAME", "KIND", "ENFORCEMENT", "POLICY SETS", "UPDATED"},
+	}
+
+	if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a policyAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			td.Rows = append(td.Rows, []string{
+				r.ID, a.Name, a.Kind, defaultStr(a.enforcement(), "-"), itoa(a.PolicySetCount), shortDate(a.UpdatedAt),
+			})
+			jsonData = append(jsonData, policyJSON{ID: r.ID, Attrs: a})
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
+
+func runPolicyShow(cmd *cobra.Command, args []string) error {
+	code, _ := cmd.Flags().GetBool("code")
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	policyID, err := resolvePolicyID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if code {
+		body, err := client.GetRaw("/policies/" + policyID + "/download")
+		if err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		defer body.Close()
+		if err := output.RenderStream(body, GetOutputOptions()); err != nil {
+			return output.NewInternalError(fmt.Sprintf("write policy code: %v", err))
+		}
+		return nil
+	}
+
+	var doc jsonapi.Document
+	if err := client.Get("/policies/"+policyID, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderPolicy(cmd, &doc, "")
+}
+
+func runPolicyCreate(cmd *cobra.Command, args []string) error {
+	kind, _ := cmd.Flags().GetString("kind")
+	file, _ := cmd.Flags().GetString("file")
+	description, _ := cmd.Flags().GetString("description")
+	enforcement, _ := cmd.Flags().GetString("enforcement")
+	query, _ := cmd.Flags().GetString("query")
+	policySets, _ := cmd.Flags().GetStringSlice("policy-set")
+
+	if file == "" {
+		return output.NewUsageError("--file is required")
+	}
+	if err := validatePolicyEnforcement(kind, enforcement); err != nil {
+		return err
+	}
+	if kind == "opa" && query == "" {
+		return output.NewUsageError("--query is required for opa policies")
+	}
+	if kind == "sentinel" && query != "" {
+		return output.NewUsageError("--query is only used with opa policies")
+	}
+	if _, err := os.Stat(file); err != nil {
+		return output.NewUsageError(fmt.Sprintf("policy file: %v", err))
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	var setIDs []string
+	for _, ps := range policySets {
+		id, err := resolvePolicySetID(client, ps)
+		if err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		setIDs = append(setIDs, id)
+	}
+
+	attrs := map[string]interface{}{
+		"name":              args[0],
+		"kind":              kind,
+		"enforcement-level": enforcement,
+	}
+	if description != "" {
+		attrs["description"] = description
+	}
+	if query != "" {
+		attrs["query"] = query
+	}
+	data := map[string]interface{}{
+		"type":       "policies",
+		"attributes": attrs,
+	}
+	if len(setIDs) > 0 {
+		data["relationships"] = map[string]interface{}{
+			"policy-sets": relationshipData("policy-sets", setIDs),
+		}
+	}
+	body := map[string]interface{}{"data": data}
+
+	var doc jsonapi.Document
+	if err := client.Post("/organizations/"+org+"/policies", body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	if err := uploadPolicyCode(client, res.ID, file); err != nil {
+		// Don't leave an empty policy behind; if it can't be removed, say so.
+		if delErr := client.Delete("/policies/" + res.ID); delErr != nil {
+			return output.NewAPIError(fmt.Sprintf("policy %s created but upload failed: %v; retry with `tfc policy update %s --file %s` (removing it also failed: %v)",
+				res.ID, err, res.ID, file, delErr))
+		}
+		return output.NewAPIError(fmt.Sprintf("%v; the new policy %s was deleted", err, res.ID))
+	}
+
+	return renderPolicy(cmd, &doc, "created")
+}
+
+func runPolicyUpdate(cmd *cobra.Command, args []string) error {
+	file, _ := cmd.Flags().GetString("file")
+
+	attrs := map[string]interface{}{}
+	for _, name := range []string{"description", "query"} {
+		if cmd.Flags().Changed(name) {
+			v, _ := cmd.Flags().GetString(name)
+			attrs[name] = v
+		}
+	}
+	if cmd.Flags().Changed("enforcement") {
+		v, _ := cmd.Flags().GetString("enforcement")
+		attrs["enforcement-level"] = v
+	}
+	if len(attrs) == 0 && file == "" {
+		return output.NewUsageError("nothing to update: pass --file, --description, --enforcement or --query")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	policyID, err := resolvePolicyID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if len(attrs) > 0 {
+		level, hasLevel := attrs["enforcement-level"].(string)
+		query, hasQuery := attrs["query"].(string)
+		if hasLevel || hasQuery {
+			var current jsonapi.Document
+			if err := client.Get("/policies/"+policyID, &current); err != nil {
+				return output.NewAPIError(err.Error())
+			}
+			res, err := jsonapi.ParseSingle(&current)
+			if err != nil {
+				return output.NewAPIError(err.Error())
+			}
+			var a policyAttrs
+			jsonapi.UnmarshalAttributes(res, &a)
+			if hasLevel {
+				if err := validatePolicyEnforcement(a.Kind, level); err != nil {
+					return err
+				}
+			}
+			if hasQuery && a.Kind == "sentinel" {
+				return output.NewUsageError("--query is only used with opa policies")
+			}
+			if hasQuery && a.Kind == "opa" && query == "" {
+				return output.NewUsageError("--query must not be empty for opa policies")
+			}
+		}
+
+		body, err := jsonapi.WrapForUpdate(policyID, "policies", attrs)
+		if err != nil {
+			return output.NewInternalError(err.Error())
+		}
+		if err := client.Patch("/policies/"+policyID, body, &doc); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	}
+
+	if file != "" {
+		if err := uploadPolicyCode(client, policyID, file); err != nil {
+			return err
+		}
+		if len(attrs) == 0 {
+			if err := client.Get("/policies/"+policyID, &doc); err != nil {
+				return output.NewAPIError(err.Error())
+			}
+		}
+	}
+
+	return renderPolicy(cmd, &doc, "updated")
+}
+
+func runPolicyDelete(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	policyID, err := resolvePolicyID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if err := client.Delete("/policies/" + policyID); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Policy %s deleted\n", policyID)
+	return nil
+}
+
+func renderPolicy(cmd *cobra.Command, doc *jsonapi.Document, verb string) error {
+	res, err := jsonapi.ParseSingle(doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a policyAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+	setIDs := extractRelationshipIDs(res, "policy-sets")
+
+	opts := GetOutputOptions()
+
+	type policyDetail struct {
+		ID           string      `json:"id"`
+		PolicySetIDs []string    `json:"policy_set_ids"`
+		Attrs        policyAttrs `json:"attributes"`
+	}
+	data := policyDetail{ID: res.ID, PolicySetIDs: setIDs, Attrs: a}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Name", a.Name},
+			{"Kind", a.Kind},
+			{"Description", defaultStr(a.Description, "-")},
+			{"Enforcement", defaultStr(a.enforcement(), "-")},
+			{"Query", defaultStr(a.Query, "-")},
+			{"Policy Sets", defaultStr(joinTags(setIDs), "-")},
+			{"Updated", a.UpdatedAt},
+		},
+	}
+
+	if verb != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Policy %s %s\n", res.ID, verb)
+	}
+	return output.RenderTable(td, data, opts)
+}
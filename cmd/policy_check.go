@@ -40,6 +40,8 @@ var policyCheckOverrideCmd = &cobra.Command{
 
 func init() {
 	policyCheckListCmd.Flags().StringVar(&flagPCRunID, "run", "", "Run ID (required)")
+	policyCheckListCmd.Flags().String("format", "", policyFormatUsage)
+	policyCheckShowCmd.Flags().String("format", "", policyFormatUsage)
 
 	policyCheckCmd.AddCommand(
 		policyCheckListCmd,
@@ -67,6 +69,10 @@ func runPolicyCheckList(cmd *cobra.Command, args []string) error {
 	if flagPCRunID == "" {
 		return output.NewUsageError("--run is required")
 	}
+	format, _ := cmd.Flags().GetString("format")
+	if err := validatePolicyFormat(format); err != nil {
+		return err
+	}
 
 	path := fmt.Sprintf("/runs/%s/policy-checks", flagPCRunID)
 
@@ -80,6 +86,16 @@ func runPolicyCheckList(cmd *cobra.Command, args []string) error {
 		return output.NewAPIError(err.Error())
 	}
 
+	if format != "" {
+		var results []policyResult
+		for _, r := range resources {
+			var a policyCheckAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			results = append(results, policyCheckResults(r.ID, a)...)
+		}
+		return renderPolicyReport(format, flagPCRunID, results)
+	}
+
 	opts := GetOutputOptions()
 
 	type pcJSON struct {
@@ -108,6 +124,11 @@ func runPolicyCheckList(cmd *cobra.Command, args []string) error {
 }
 
 func runPolicyCheckShow(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	if err := validatePolicyFormat(format); err != nil {
+		return err
+	}
+
 	client, err := newClient()
 	if err != nil {
 		return err
@@ -127,6 +148,10 @@ func runPolicyCheckShow(cmd *cobra.Command, args []string) error {
 	var a policyCheckAttrs
 	jsonapi.UnmarshalAttributes(res, &a)
 
+	if format != "" {
+		return renderPolicyReport(format, res.ID, policyCheckResults(res.ID, a))
+	}
+
 	overridable := "no"
 	if a.Actions != nil && a.Actions["is-overridable"] {
 		overridable = "yes"
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+)
+
+// Policy report formats for CI systems.
+const (
+	policyFormatJUnit = "junit"
+	policyFormatSARIF = "sarif"
+)
+
+const policyFormatUsage = "Report format for CI: junit or sarif"
+
+// validatePolicyFormat checks a --format value; "" keeps the normal output.
+func validatePolicyFormat(format string) error {
+	switch format {
+	case "", policyFormatJUnit, policyFormatSARIF:
+	default:
+		return output.NewUsageError(fmt.Sprintf("invalid --format %q: must be junit or sarif", format))
+	}
+	if format != "" && (flagJSON || flagPlaintext || flagTemplate != "") {
+		return output.NewUsageError("--format cannot be combined with --json, --plaintext or --template")
+	}
+	return nil
+}
+
+// renderPolicyReport writes results as a JUnit or SARIF report.
+func renderPolicyReport(format, name string, results []policyResult) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch format {
+	case policyFormatJUnit:
+		data, err = junitReport(name, results)
+	case policyFormatSARIF:
+		data, err = sarifReport(results)
+	}
+	if err != nil {
+		return output.NewInternalError(fmt.Sprintf("%s report: %v", format, err))
+	}
+	return output.RenderStream(bytes.NewReader(data), GetOutputOptions())
+}
+
+// policyName is the name of the policy a result is for, falling back to the
+// policy set when the whole set errored.
+func (r policyResult) policyName() string {
+	if r.Policy == "" {
+		return r.PolicySet
+	}
+	return r.Policy
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// junitReport renders results as JUnit XML with one test suite per policy
+// set and one test case per policy. Errored policies are test errors; any
+// other result that did not pass is a failure typed by enforcement level.
+func junitReport(name string, results []policyResult) ([]byte, error) {
+	report := junitSuites{Name: name}
+	index := map[string]int{}
+	for _, r := range results {
+		i, ok := index[r.PolicySet]
+		if !ok {
+			i = len(report.Suites)
+			index[r.PolicySet] = i
+			report.Suites = append(report.Suites, junitSuite{Name: r.PolicySet})
+		}
+		suite := &report.Suites[i]
+
+		tc := junitCase{Name: r.policyName(), Classname: r.PolicySet}
+		enforcement := defaultStr(r.Enforcement, "unknown")
+		switch {
+		case r.Status == "errored":
+			tc.Error = &junitProblem{
+				Message: fmt.Sprintf("policy %s errored", tc.Name),
+				Body:    r.Output,
+			}
+			suite.Errors++
+		case r.failed():
+			tc.Failure = &junitProblem{
+				Message: fmt.Sprintf("%s policy %s %s", enforcement, tc.Name, r.Status),
+				Type:    enforcement,
+				Body:    r.Output,
+			}
+			suite.Failures++
+		default:
+			tc.SystemOut = r.Output
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+	for _, s := range report.Suites {
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		report.Errors += s.Errors
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(xml.Header), data...), '\n'), nil
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	Properties       sarifProps   `json:"properties"`
+}
+
+type sarifProps struct {
+	PolicySet   string `json:"policySet"`
+	Kind        string `json:"kind,omitempty"`
+	Enforcement string `json:"enforcement,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysical `json:"physicalLocation"`
+}
+
+type sarifPhysical struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+// sarifLevel maps an enforcement level to a SARIF result level.
+func sarifLevel(r policyResult) string {
+	if r.Status == "errored" {
+		return "error"
+	}
+	switch r.Enforcement {
+	case "advisory":
+		return "note"
+	case "soft-mandatory":
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// sarifReport renders results as SARIF 2.1.0. Every policy becomes a rule;
+// only policies that did not pass become results. Policies have no source
+// file in the run, so results are located at <policy-set>/<policy>.
+func sarifReport(results []policyResult) ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "tfc",
+			InformationURI: "https://developer.hashicorp.com/terraform/cloud-docs/policy-enforcement",
+			Version:        appVersion,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+	index := map[string]int{}
+	for _, r := range results {
+		id := r.PolicySet + "/" + r.policyName()
+		i, ok := index[id]
+		if !ok {
+			i = len(run.Tool.Driver.Rules)
+			index[id] = i
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               id,
+				Name:             r.policyName(),
+				ShortDescription: sarifMessage{Text: fmt.Sprintf("Policy %s in policy set %s", r.policyName(), r.PolicySet)},
+				Properties:       sarifProps{PolicySet: r.PolicySet, Kind: r.Kind, Enforcement: r.Enforcement},
+			})
+		}
+		if !r.failed() {
+			continue
+		}
+
+		text := fmt.Sprintf("%s policy %s %s", defaultStr(r.Enforcement, "unknown"), r.policyName(), r.Status)
+		if r.Output != "" {
+			text += ": " + r.Output
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    id,
+			RuleIndex: i,
+			Level:     sarifLevel(r),
+			Message:   sarifMessage{Text: text},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysical{ArtifactLocation: sarifArtifact{URI: id}}}},
+		})
+	}
+
+	data, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testPolicyResults() []policyResult {
+	return []policyResult{
+		{PolicySet: "networking", Policy: "restrict-cidr", Kind: "sentinel", Enforcement: "soft-mandatory", Status: "failed", Output: "0.0.0.0/0 is not allowed"},
+		{PolicySet: "networking", Policy: "tags", Kind: "sentinel", Enforcement: "advisory", Status: "passed"},
+		{PolicySet: "opa-baseline", Kind: "opa", Status: "errored", Output: "rego parse error"},
+	}
+}
+
+func TestJUnitReport(t *testing.T) {
+	data, err := junitReport("run-abc", testPolicyResults())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, data)
+	}
+	if report.Name != "run-abc" || report.Tests != 3 || report.Failures != 1 || report.Errors != 1 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+	if len(report.Suites) != 2 || report.Suites[0].Name != "networking" || report.Suites[0].Tests != 2 {
+		t.Fatalf("unexpected suites: %+v", report.Suites)
+	}
+	failure := report.Suites[0].Cases[0].Failure
+	if failure == nil || failure.Type != "soft-mandatory" || failure.Body != "0.0.0.0/0 is not allowed" {
+		t.Errorf("unexpected failure: %+v", failure)
+	}
+	if report.Suites[0].Cases[1].Failure != nil {
+		t.Error("passing policy should have no failure")
+	}
+	errored := report.Suites[1].Cases[0]
+	if errored.Name != "opa-baseline" || errored.Error == nil || errored.Error.Body != "rego parse error" {
+		t.Errorf("unexpected errored case: %+v", errored)
+	}
+}
+
+func TestSARIFReport(t *testing.T) {
+	data, err := sarifReport(testPolicyResults())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log: %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 3 {
+		t.Errorf("expected a rule per policy, got %d", len(run.Tool.Driver.Rules))
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("expected results only for policies that did not pass, got %d", len(run.Results))
+	}
+	r := run.Results[0]
+	if r.RuleID != "networking/restrict-cidr" || r.Level != "warning" || r.RuleIndex != 0 ||
+		!strings.Contains(r.Message.Text, "0.0.0.0/0 is not allowed") {
+		t.Errorf("unexpected result: %+v", r)
+	}
+	if run.Results[1].Level != "error" || run.Results[1].RuleIndex != 2 {
+		t.Errorf("unexpected errored result: %+v", run.Results[1])
+	}
+}
+
+func TestValidatePolicyFormat(t *testing.T) {
+	flagJSON = false
+	for _, f := range []string{"", "junit", "sarif"} {
+		if err := validatePolicyFormat(f); err != nil {
+			t.Errorf("format %q: unexpected error %v", f, err)
+		}
+	}
+	if err := validatePolicyFormat("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+	flagJSON = true
+	defer func() { flagJSON = false }()
+	if err := validatePolicyFormat("junit"); err == nil {
+		t.Error("expected error combining --format with --json")
+	}
+}
+
+func TestPolicyCheckShow_JUnit(t *testing.T) {
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path != "/api/v2/policy-checks/polchk-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":{"id":"polchk-1","type":"policy-checks","attributes":{"status":"soft_failed","result":` + testPolicyCheckResult + `}}}`))
+	})
+	defer ts.Close()
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	out := filepath.Join(t.TempDir(), "report.xml")
+	t.Cleanup(func() {
+		policyCheckShowCmd.Flags().Set("format", "")
+		flagOutputFile = ""
+	})
+	rootCmd.SetArgs([]string{"pc", "show", "polchk-1", "--format", "junit", "--json=false", "-o", out})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report junitSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, data)
+	}
+	if report.Tests != 2 || report.Failures != 1 || report.Suites[0].Cases[0].Name != "restrict-cidr" {
+		t.Errorf("unexpected report: %s", data)
+	}
+}
@@ -1,31 +1,669 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
 
 var policySetCmd = &cobra.Command{
 	Use:     "policy-set",
 	Aliases: []string{"ps"},
-	Short:   "View policy sets",
+	Short:   "Manage policy sets",
 }
 
 var policySetListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List policy sets in an organization",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("policy-set list")
-	},
+	RunE:  runPolicySetList,
 }
 
 var policySetShowCmd = &cobra.Command{
-	Use:   "show [id]",
+	Use:   "show [policy-set]",
 	Short: "Show policy set details",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("policy-set show")
-	},
+	RunE:  runPolicySetShow,
+}
+
+var policySetCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a policy set",
+	Long: `Create a policy set.
+
+A policy set gets its policies in one of three ways:
+
+  --policy          individually managed policies ("tfc policy create")
+  --vcs-repo        a VCS repository, with --oauth-token-id and optionally
+                    --vcs-branch and --policies-path
+  (neither)         versioned uploads with "tfc policy-set upload"
+
+Attach it to everything with --global, or to specific --workspace and
+--project targets.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPolicySetCreate,
+}
+
+var policySetUpdateCmd = &cobra.Command{
+	Use:   "update [policy-set]",
+	Short: "Update a policy set",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolicySetUpdate,
+}
+
+var policySetDeleteCmd = &cobra.Command{
+	Use:   "delete [policy-set]",
+	Short: "Delete a policy set",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolicySetDelete,
+}
+
+var policySetAttachCmd = &cobra.Command{
+	Use:   "attach [policy-set]",
+	Short: "Attach a policy set to workspaces and projects",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolicySetAttach,
+}
+
+var policySetDetachCmd = &cobra.Command{
+	Use:   "detach [policy-set]",
+	Short: "Detach a policy set from workspaces and projects",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolicySetDetach,
+}
+
+var policySetUploadCmd = &cobra.Command{
+	Use:   "upload [policy-set] [dir]",
+	Short: "Upload a directory as a new policy set version",
+	Long: `Upload a directory as a new policy set version.
+
+The directory is packed into a tarball (skipping .git) and uploaded, then the
+version is polled until it is ready or has errored.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPolicySetUpload,
 }
 
 func init() {
-	policySetCmd.AddCommand(policySetListCmd, policySetShowCmd)
+	policySetListCmd.Flags().String("kind", "", "Only policy sets of this kind: sentinel or opa")
+	policySetListCmd.Flags().String("search", "", "Only policy sets whose name contains this")
+
+	policySetCreateCmd.Flags().String("kind", "sentinel", "Policy kind: sentinel or opa")
+	policySetCreateCmd.Flags().String("description", "", "Description")
+	policySetCreateCmd.Flags().Bool("global", false, "Apply to every workspace in the organization")
+	policySetCreateCmd.Flags().Bool("overridable", false, "Allow failed mandatory OPA policies to be overridden")
+	policySetCreateCmd.Flags().StringSlice("policy", nil, "Policy to include (name or ID, repeatable)")
+	policySetCreateCmd.Flags().StringSlice("workspace", nil, "Workspace to attach to (name or ID, repeatable)")
+	policySetCreateCmd.Flags().StringSlice("project", nil, "Project to attach to (name or ID, repeatable)")
+	policySetCreateCmd.Flags().String("vcs-repo", "", "VCS repository identifier, e.g. org/repo")
+	policySetCreateCmd.Flags().String("vcs-branch", "", "VCS branch (default branch if empty)")
+	policySetCreateCmd.Flags().String("oauth-token-id", "", "OAuth token ID of the VCS connection")
+	policySetCreateCmd.Flags().String("policies-path", "", "Subdirectory of the repository or upload holding the policies")
+
+	policySetUpdateCmd.Flags().String("name", "", "New name")
+	policySetUpdateCmd.Flags().String("description", "", "Description")
+	policySetUpdateCmd.Flags().Bool("global", false, "Apply to every workspace in the organization")
+	policySetUpdateCmd.Flags().Bool("overridable", false, "Allow failed mandatory OPA policies to be overridden")
+	policySetUpdateCmd.Flags().String("policies-path", "", "Subdirectory holding the policies")
+
+	for _, c := range []*cobra.Command{policySetAttachCmd, policySetDetachCmd} {
+		c.Flags().StringSlice("workspace", nil, "Workspace name or ID (repeatable)")
+		c.Flags().StringSlice("project", nil, "Project name or ID (repeatable)")
+	}
+
+	policySetUploadCmd.Flags().Duration("timeout", 2*time.Minute, "How long to wait for the version to be processed")
+
+	policySetCmd.AddCommand(
+		policySetListCmd,
+		policySetShowCmd,
+		policySetCreateCmd,
+		policySetUpdateCmd,
+		policySetDeleteCmd,
+		policySetAttachCmd,
+		policySetDetachCmd,
+		policySetUploadCmd,
+	)
 	rootCmd.AddCommand(policySetCmd)
 }
+
+type policySetAttrs struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	Kind              string `json:"kind"`
+	Global            bool   `json:"global"`
+	Overridable       bool   `json:"overridable"`
+	Versioned         bool   `json:"versioned"`
+	PoliciesPath      string `json:"policies-path"`
+	PolicyToolVersion string `json:"policy-tool-version"`
+	PolicyCount       int    `json:"policy-count"`
+	WorkspaceCount    int    `json:"workspace-count"`
+	ProjectCount      int    `json:"project-count"`
+	VCSRepo           *struct {
+		Identifier   string `json:"identifier"`
+		Branch       string `json:"branch"`
+		OAuthTokenID string `json:"oauth-token-id"`
+	} `json:"vcs-repo"`
+	CreatedAt string `json:"created-at"`
+	UpdatedAt string `json:"updated-at"`
+}
+
+// source describes where a policy set gets its policies from.
+func (a policySetAttrs) source() string {
+	switch {
+	case a.VCSRepo != nil:
+		return "vcs:" + a.VCSRepo.Identifier
+	case a.Versioned:
+		return "upload"
+	default:
+		return "policies"
+	}
+}
+
+type policySetVersionAttrs struct {
+	Source    string `json:"source"`
+	Status    string `json:"status"`
+	Error     string `json:"error"`
+	CreatedAt string `json:"created-at"`
+}
+
+// resolvePolicySetID resolves a policy set name or ID to a policy set ID.
+// If the value starts with "polset-", it is returned as-is.
+func resolvePolicySetID(client *api.Client, policySet string) (string, error) {
+	if strings.HasPrefix(policySet, "polset-") {
+		return policySet, nil
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	path := fmt.Sprintf("/organizations/%s/policy-sets?search[name]=%s&page[size]=100", org, url.QueryEscape(policySet))
+	if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a policySetAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			if a.Name == policySet && id == "" {
+				id = r.ID
+			}
+		}
+	}); err != nil {
+		return "", fmt.Errorf("resolve policy set %q: %w", policySet, err)
+	}
+	if id == "" {
+		return "", fmt.Errorf("resolve policy set %q: not found in organization %s", policySet, org)
+	}
+	return id, nil
+}
+
+// policySetTargets resolves the --workspace and --project flags to IDs.
+func policySetTargets(cmd *cobra.Command) (wsIDs, projectIDs []string, err error) {
+	workspaces, _ := cmd.Flags().GetStringSlice("workspace")
+	projects, _ := cmd.Flags().GetStringSlice("project")
+	for _, ws := range workspaces {
+		id, err := resolveWorkspaceID(ws)
+		if err != nil {
+			return nil, nil, output.NewAPIError(err.Error())
+		}
+		wsIDs = append(wsIDs, id)
+	}
+	for _, p := range projects {
+		id, err := resolveProjectID(p)
+		if err != nil {
+			return nil, nil, output.NewAPIError(err.Error())
+		}
+		projectIDs = append(projectIDs, id)
+	}
+	return wsIDs, projectIDs, nil
+}
+
+func runPolicySetList(cmd *cobra.Command, args []string) error {
+	kind, _ := cmd.Flags().GetString("kind")
+	search, _ := cmd.Flags().GetString("search")
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/organizations/%s/policy-sets?page[size]=100", org)
+	if kind != "" {
+		path += "&filter[kind]=" + url.QueryEscape(kind)
+	}
+	if search != "" {
+		path += "&search[name]=" + url.QueryEscape(search)
+	}
+
+	opts := GetOutputOptions()
+
+	type policySetJSON struct {
+		ID    string         `json:"id"`
+		Attrs policySetAttrs `json:"attributes"`
+	}
+	var jsonData []policySetJSON
+	td := output.TableData{
+		Headers: []string{"ID", "NAME", "KIND", "GLOBAL", "SOURCE", "POLICIES", "WORKSPACES", "PROJECTS"},
+	}
+
+	if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a policySetAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			td.Rows = append(td.Rows, []string{
+				r.ID, a.Name, a.Kind, boolStr(a.Global), truncateStr(a.source(), 30),
+				itoa(a.PolicyCount), itoa(a.WorkspaceCount), itoa(a.ProjectCount),
+			})
+			jsonData = append(jsonData, policySetJSON{ID: r.ID, Attrs: a})
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
+
+func runPolicySetShow(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	psID, err := resolvePolicySetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Get("/policy-sets/"+psID+"?include=policies,workspaces,projects,newest_version", &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderPolicySet(cmd, &doc, "")
+}
+
+func runPolicySetCreate(cmd *cobra.Command, args []string) error {
+	kind, _ := cmd.Flags().GetString("kind")
+	description, _ := cmd.Flags().GetString("description")
+	global, _ := cmd.Flags().GetBool("global")
+	overridable, _ := cmd.Flags().GetBool("overridable")
+	policies, _ := cmd.Flags().GetStringSlice("policy")
+	vcsRepo, _ := cmd.Flags().GetString("vcs-repo")
+	vcsBranch, _ := cmd.Flags().GetString("vcs-branch")
+	oauthTokenID, _ := cmd.Flags().GetString("oauth-token-id")
+	policiesPath, _ := cmd.Flags().GetString("policies-path")
+
+	if !containsStr(policyKinds, kind) {
+		return output.NewUsageError(fmt.Sprintf("--kind must be one of %s", strings.Join(policyKinds, ", ")))
+	}
+	if overridable && kind != "opa" {
+		return output.NewUsageError("--overridable is only used with opa policy sets")
+	}
+	if vcsRepo != "" && oauthTokenID == "" {
+		return output.NewUsageError("--vcs-repo requires --oauth-token-id")
+	}
+	if vcsRepo == "" && (vcsBranch != "" || oauthTokenID != "") {
+		return output.NewUsageError("--vcs-branch and --oauth-token-id are only used with --vcs-repo")
+	}
+	if vcsRepo != "" && len(policies) > 0 {
+		return output.NewUsageError("--policy cannot be combined with --vcs-repo; VCS policy sets read policies from the repository")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	wsIDs, projectIDs, err := policySetTargets(cmd)
+	if err != nil {
+		return err
+	}
+	if global && (len(wsIDs) > 0 || len(projectIDs) > 0) {
+		return output.NewUsageError("--global cannot be combined with --workspace or --project")
+	}
+	var policyIDs []string
+	for _, p := range policies {
+		id, err := resolvePolicyID(client, p)
+		if err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		policyIDs = append(policyIDs, id)
+	}
+
+	attrs := map[string]interface{}{
+		"name":   args[0],
+		"kind":   kind,
+		"global": global,
+	}
+	if description != "" {
+		attrs["description"] = description
+	}
+	if kind == "opa" {
+		attrs["overridable"] = overridable
+	}
+	if policiesPath != "" {
+		attrs["policies-path"] = policiesPath
+	}
+	if vcsRepo != "" {
+		repo := map[string]interface{}{
+			"identifier":     vcsRepo,
+			"oauth-token-id": oauthTokenID,
+		}
+		if vcsBranch != "" {
+			repo["branch"] = vcsBranch
+		}
+		attrs["vcs-repo"] = repo
+	}
+
+	rels := map[string]interface{}{}
+	if len(policyIDs) > 0 {
+		rels["policies"] = relationshipData("policies", policyIDs)
+	}
+	if len(wsIDs) > 0 {
+		rels["workspaces"] = relationshipData("workspaces", wsIDs)
+	}
+	if len(projectIDs) > 0 {
+		rels["projects"] = relationshipData("projects", projectIDs)
+	}
+	data := map[string]interface{}{
+		"type":       "policy-sets",
+		"attributes": attrs,
+	}
+	if len(rels) > 0 {
+		data["relationships"] = rels
+	}
+	body := map[string]interface{}{"data": data}
+
+	var doc jsonapi.Document
+	if err := client.Post("/organizations/"+org+"/policy-sets", body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderPolicySet(cmd, &doc, "created")
+}
+
+func runPolicySetUpdate(cmd *cobra.Command, args []string) error {
+	attrs := map[string]interface{}{}
+	for _, name := range []string{"name", "description", "policies-path"} {
+		if cmd.Flags().Changed(name) {
+			v, _ := cmd.Flags().GetString(name)
+			attrs[name] = v
+		}
+	}
+	for _, name := range []string{"global", "overridable"} {
+		if cmd.Flags().Changed(name) {
+			v, _ := cmd.Flags().GetBool(name)
+			attrs[name] = v
+		}
+	}
+	if len(attrs) == 0 {
+		return output.NewUsageError("nothing to update: pass at least one flag")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	psID, err := resolvePolicySetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	body, err := jsonapi.WrapForUpdate(psID, "policy-sets", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Patch("/policy-sets/"+psID, body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderPolicySet(cmd, &doc, "updated")
+}
+
+func runPolicySetDelete(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	psID, err := resolvePolicySetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if err := client.Delete("/policy-sets/" + psID); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Policy set %s deleted\n", psID)
+	return nil
+}
+
+func runPolicySetAttach(cmd *cobra.Command, args []string) error {
+	return changePolicySetTargets(cmd, args[0], true)
+}
+
+func runPolicySetDetach(cmd *cobra.Command, args []string) error {
+	return changePolicySetTargets(cmd, args[0], false)
+}
+
+// changePolicySetTargets attaches or detaches the --workspace and --project
+// targets.
+func changePolicySetTargets(cmd *cobra.Command, policySet string, attach bool) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	psID, err := resolvePolicySetID(client, policySet)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	wsIDs, projectIDs, err := policySetTargets(cmd)
+	if err != nil {
+		return err
+	}
+	if len(wsIDs) == 0 && len(projectIDs) == 0 {
+		return output.NewUsageError("pass at least one --workspace or --project")
+	}
+
+	send := func(relationship string, ids []string) error {
+		if len(ids) == 0 {
+			return nil
+		}
+		path := fmt.Sprintf("/policy-sets/%s/relationships/%s", psID, relationship)
+		body := relationshipData(relationship, ids)
+		if attach {
+			return client.Post(path, body, nil)
+		}
+		return client.DeleteWithBody(path, body)
+	}
+	if err := send("workspaces", wsIDs); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	if err := send("projects", projectIDs); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	verb := "attached to"
+	if !attach {
+		verb = "detached from"
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Policy set %s %s %d workspace(s) and %d project(s)\n", psID, verb, len(wsIDs), len(projectIDs))
+	return nil
+}
+
+func runPolicySetUpload(cmd *cobra.Command, args []string) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	dir := args[1]
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return output.NewUsageError(fmt.Sprintf("%s is not a directory", dir))
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	psID, err := resolvePolicySetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	archive, err := createArchive(dir, func(rel string, isDir bool) bool {
+		return isDir && rel == ".git"
+	})
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post("/policy-sets/"+psID+"/versions", nil, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	uploadURL, _ := res.Links["upload"].(string)
+	if uploadURL == "" {
+		return output.NewAPIError(fmt.Sprintf("policy set version %s has no upload link", res.ID))
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Uploading %s (%d bytes) to policy set version %s\n", dir, archive.Len(), res.ID)
+	if err := uploadArchive(uploadURL, archive); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a policySetVersionAttrs
+	err = waitFor(timeout, func() (bool, error) {
+		var vdoc jsonapi.Document
+		if err := client.Get("/policy-set-versions/"+res.ID, &vdoc); err != nil {
+			return false, err
+		}
+		vres, err := jsonapi.ParseSingle(&vdoc)
+		if err != nil {
+			return false, err
+		}
+		jsonapi.UnmarshalAttributes(vres, &a)
+		return a.Status == "ready" || a.Status == "errored", nil
+	})
+	if errors.Is(err, errWaitTimeout) {
+		return output.NewTimeoutError(fmt.Sprintf("policy set version %s: %v", res.ID, err))
+	}
+	if err != nil {
+		return output.NewAPIError(fmt.Sprintf("policy set version %s: %v", res.ID, err))
+	}
+	if a.Status == "errored" {
+		return output.NewAPIError(fmt.Sprintf("policy set version %s errored: %s", res.ID, defaultStr(a.Error, "no details")))
+	}
+
+	opts := GetOutputOptions()
+
+	type versionDetail struct {
+		ID          string                `json:"id"`
+		PolicySetID string                `json:"policy_set_id"`
+		Attrs       policySetVersionAttrs `json:"attributes"`
+	}
+	data := versionDetail{ID: res.ID, PolicySetID: psID, Attrs: a}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Policy Set", psID},
+			{"Source", a.Source},
+			{"Status", a.Status},
+			{"Created", a.CreatedAt},
+		},
+	}
+
+	return output.RenderTable(td, data, opts)
+}
+
+func renderPolicySet(cmd *cobra.Command, doc *jsonapi.Document, verb string) error {
+	res, err := jsonapi.ParseSingle(doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a policySetAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	names := map[string]string{}
+	var newestStatus string
+	for _, inc := range doc.Included {
+		var n struct {
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		}
+		jsonapi.UnmarshalAttributes(&inc, &n)
+		names[inc.ID] = n.Name
+		if inc.Type == "policy-set-versions" {
+			newestStatus = n.Status
+		}
+	}
+	nameList := func(ids []string) []string {
+		var out []string
+		for _, id := range ids {
+			out = append(out, defaultStr(names[id], id))
+		}
+		return out
+	}
+
+	policyIDs := extractRelationshipIDs(res, "policies")
+	wsIDs := extractRelationshipIDs(res, "workspaces")
+	projectIDs := extractRelationshipIDs(res, "projects")
+
+	opts := GetOutputOptions()
+
+	type policySetDetail struct {
+		ID           string         `json:"id"`
+		PolicyIDs    []string       `json:"policy_ids"`
+		WorkspaceIDs []string       `json:"workspace_ids"`
+		ProjectIDs   []string       `json:"project_ids"`
+		Attrs        policySetAttrs `json:"attributes"`
+	}
+	data := policySetDetail{ID: res.ID, PolicyIDs: policyIDs, WorkspaceIDs: wsIDs, ProjectIDs: projectIDs, Attrs: a}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Name", a.Name},
+			{"Kind", a.Kind},
+			{"Description", defaultStr(a.Description, "-")},
+			{"Global", boolStr(a.Global)},
+			{"Overridable", boolStr(a.Overridable)},
+			{"Source", a.source()},
+			{"Policies Path", defaultStr(a.PoliciesPath, "-")},
+			{"Latest Version", defaultStr(newestStatus, "-")},
+			{"Policies", defaultStr(joinTags(nameList(policyIDs)), "-")},
+			{"Workspaces", defaultStr(joinTags(nameList(wsIDs)), "-")},
+			{"Projects", defaultStr(joinTags(nameList(projectIDs)), "-")},
+			{"Updated", a.UpdatedAt},
+		},
+	}
+
+	if verb != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Policy set %s %s\n", res.ID, verb)
+	}
+	return output.RenderTable(td, data, opts)
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var policySetParamCmd = &cobra.Command{
+	Use:   "param",
+	Short: "Manage policy set parameters",
+	Long: `Manage policy set parameters.
+
+Parameters are key/value pairs passed to Sentinel policies in the set.`,
+}
+
+var policySetParamListCmd = &cobra.Command{
+	Use:   "list [policy-set]",
+	Short: "List policy set parameters",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolicySetParamList,
+}
+
+var policySetParamSetCmd = &cobra.Command{
+	Use:   "set [policy-set] [key] [value]",
+	Short: "Create or update a policy set parameter",
+	Args:  cobra.ExactArgs(3),
+	RunE:  runPolicySetParamSet,
+}
+
+var policySetParamDeleteCmd = &cobra.Command{
+	Use:   "delete [policy-set] [key]",
+	Short: "Delete a policy set parameter by key or ID",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runPolicySetParamDelete,
+}
+
+func init() {
+	policySetParamSetCmd.Flags().Bool("sensitive", false, "Mark the parameter as sensitive")
+
+	policySetParamCmd.AddCommand(policySetParamListCmd, policySetParamSetCmd, policySetParamDeleteCmd)
+	policySetCmd.AddCommand(policySetParamCmd)
+}
+
+type policySetParamAttrs struct {
+	Key       string `json:"key"`
+	Value     string `json:"value"`
+	Sensitive bool   `json:"sensitive"`
+	Category  string `json:"category"`
+}
+
+// findPolicySetParam returns the ID of the parameter with the given key or
+// ID, or "" if there is none.
+func findPolicySetParam(client *api.Client, psID, keyOrID string) (string, error) {
+	var id string
+	err := client.GetAllPages("/policy-sets/"+psID+"/parameters?page[size]=100", func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a policySetParamAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			if r.ID == keyOrID || a.Key == keyOrID {
+				id = r.ID
+			}
+		}
+	})
+	return id, err
+}
+
+func runPolicySetParamList(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	psID, err := resolvePolicySetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	opts := GetOutputOptions()
+
+	type paramJSON struct {
+		ID    string              `json:"id"`
+		Attrs policySetParamAttrs `json:"attributes"`
+	}
+	var jsonData []paramJSON
+	td := output.TableData{
+		Headers: []string{"ID", "KEY", "VALUE", "SENSITIVE"},
+	}
+
+	if err := client.GetAllPages("/policy-sets/"+psID+"/parameters?page[size]=100", func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a policySetParamAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			value := a.Value
+			if a.Sensitive {
+				value = "(sensitive)"
+			}
+			td.Rows = append(td.Rows, []string{r.ID, a.Key, truncateStr(value, 50), boolStr(a.Sensitive)})
+			jsonData = append(jsonData, paramJSON{ID: r.ID, Attrs: a})
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
+
+func runPolicySetParamSet(cmd *cobra.Command, args []string) error {
+	key, value := args[1], args[2]
+	if strings.TrimSpace(key) == "" {
+		return output.NewUsageError("key must not be empty")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	psID, err := resolvePolicySetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	paramID, err := findPolicySetParam(client, psID, key)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	attrs := map[string]interface{}{
+		"key":   key,
+		"value": value,
+	}
+	if cmd.Flags().Changed("sensitive") || paramID == "" {
+		sensitive, _ := cmd.Flags().GetBool("sensitive")
+		attrs["sensitive"] = sensitive
+	}
+
+	var doc jsonapi.Document
+	verb := "updated"
+	if paramID == "" {
+		attrs["category"] = "policy-set"
+		body, err := jsonapi.WrapForCreate("vars", attrs)
+		if err != nil {
+			return output.NewInternalError(err.Error())
+		}
+		if err := client.Post("/policy-sets/"+psID+"/parameters", body, &doc); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		verb = "created"
+	} else {
+		body, err := jsonapi.WrapForUpdate(paramID, "vars", attrs)
+		if err != nil {
+			return output.NewInternalError(err.Error())
+		}
+		if err := client.Patch("/policy-sets/"+psID+"/parameters/"+paramID, body, &doc); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	}
+
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a policySetParamAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	opts := GetOutputOptions()
+
+	type paramDetail struct {
+		ID    string              `json:"id"`
+		Attrs policySetParamAttrs `json:"attributes"`
+	}
+	data := paramDetail{ID: res.ID, Attrs: a}
+
+	shown := a.Value
+	if a.Sensitive {
+		shown = "(sensitive)"
+	}
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Key", a.Key},
+			{"Value", shown},
+			{"Sensitive", boolStr(a.Sensitive)},
+		},
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Parameter %s %s\n", a.Key, verb)
+	return output.RenderTable(td, data, opts)
+}
+
+func runPolicySetParamDelete(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	psID, err := resolvePolicySetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	paramID, err := findPolicySetParam(client, psID, args[1])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	if paramID == "" {
+		return output.NewNotFoundError(fmt.Sprintf("policy set %s has no parameter %q", psID, args[1]))
+	}
+
+	if err := client.Delete("/policy-sets/" + psID + "/parameters/" + paramID); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Parameter %s deleted\n", args[1])
+	return nil
+}
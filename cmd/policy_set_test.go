@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+)
+
+// archiveEntries lists the names in a gzipped tarball.
+func archiveEntries(t *testing.T, data []byte) []string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestCreateArchive_Skip(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "policies"), 0o755)
+	os.MkdirAll(filepath.Join(dir, ".git", "objects"), 0o755)
+	os.WriteFile(filepath.Join(dir, "sentinel.hcl"), []byte("policy \"a\" {}"), 0o644)
+	os.WriteFile(filepath.Join(dir, "policies", "a.sentinel"), []byte("main = rule { true }"), 0o644)
+	os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("ref"), 0o644)
+
+	buf, err := createArchive(dir, func(rel string, isDir bool) bool {
+		return isDir && rel == ".git"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Join(archiveEntries(t, buf.Bytes()), ",")
+	want := "policies/,policies/a.sentinel,sentinel.hcl"
+	if got != want {
+		t.Errorf("archive entries = %s, want %s", got, want)
+	}
+}
+
+func TestPolicySetUpload(t *testing.T) {
+	oldInterval := pollInterval
+	pollInterval = time.Millisecond
+	defer func() { pollInterval = oldInterval }()
+
+	var uploaded []byte
+	polls := 0
+
+	var tsURL string
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v2/policy-sets/polset-abc/versions":
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":         "polsetver-1",
+					"type":       "policy-set-versions",
+					"attributes": map[string]interface{}{"status": "pending"},
+					"links":      map[string]interface{}{"upload": tsURL + "/upload/secret"},
+				},
+			})
+		case r.Method == "PUT" && r.URL.Path == "/upload/secret":
+			if r.Header.Get("Authorization") != "" {
+				t.Error("upload URL should not receive the API token")
+			}
+			uploaded, _ = io.ReadAll(r.Body)
+			w.WriteHeader(200)
+		case r.Method == "GET" && r.URL.Path == "/api/v2/policy-set-versions/polsetver-1":
+			polls++
+			status := "pending"
+			if polls > 1 {
+				status = "ready"
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":         "polsetver-1",
+					"type":       "policy-set-versions",
+					"attributes": map[string]interface{}{"status": status, "source": "tfe-api"},
+				},
+			})
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+	tsURL = ts.URL
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "sentinel.hcl"), []byte("policy \"a\" {}"), 0o644)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"policy-set", "upload", "polset-abc", dir, "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := archiveEntries(t, uploaded); len(got) != 1 || got[0] != "sentinel.hcl" {
+		t.Errorf("unexpected uploaded archive entries: %v", got)
+	}
+	if polls < 2 {
+		t.Errorf("expected version to be polled until ready, polled %d times", polls)
+	}
+}
+
+func TestPolicySetUpload_TimeoutError(t *testing.T) {
+	oldInterval := pollInterval
+	pollInterval = time.Millisecond
+	defer func() { pollInterval = oldInterval }()
+
+	var tsURL string
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v2/policy-sets/polset-abc/versions":
+			w.WriteHeader(201)
+			w.Write([]byte(`{"data":{"id":"polsetver-1","type":"policy-set-versions","attributes":{"status":"pending"},
+				"links":{"upload":"` + tsURL + `/upload/secret"}}}`))
+		case r.Method == "PUT" && r.URL.Path == "/upload/secret":
+		case r.Method == "GET" && r.URL.Path == "/api/v2/policy-set-versions/polsetver-1":
+			w.Write([]byte(`{"data":{"id":"polsetver-1","type":"policy-set-versions","attributes":{"status":"pending"}}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+	tsURL = ts.URL
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "sentinel.hcl"), []byte("policy \"a\" {}"), 0o644)
+
+	defer policySetUploadCmd.Flags().Set("timeout", "2m")
+	rootCmd.SetArgs([]string{"policy-set", "upload", "polset-abc", dir, "--timeout", "10ms", "--json=false"})
+	err := rootCmd.Execute()
+
+	var se *output.StructuredError
+	if !errors.As(err, &se) || se.Type != output.ErrTypeTimeout {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+}
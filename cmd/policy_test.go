@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidatePolicyEnforcement(t *testing.T) {
+	tests := []struct {
+		kind, level string
+		ok          bool
+	}{
+		{"sentinel", "hard-mandatory", true},
+		{"sentinel", "mandatory", false},
+		{"opa", "mandatory", true},
+		{"opa", "soft-mandatory", false},
+		{"rego", "advisory", false},
+	}
+	for _, tt := range tests {
+		err := validatePolicyEnforcement(tt.kind, tt.level)
+		if (err == nil) != tt.ok {
+			t.Errorf("validatePolicyEnforcement(%q, %q) = %v, want ok=%v", tt.kind, tt.level, err, tt.ok)
+		}
+	}
+}
+
+func TestPolicyCreate_UploadsCode(t *testing.T) {
+	var createBody map[string]interface{}
+	var uploaded, uploadType string
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v2/organizations/test-org/policies":
+			json.NewDecoder(r.Body).Decode(&createBody)
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "pol-new",
+					"type": "policies",
+					"attributes": map[string]interface{}{
+						"name": "deny-public", "kind": "opa", "enforcement-level": "mandatory",
+					},
+				},
+			})
+		case r.Method == "PUT" && r.URL.Path == "/api/v2/policies/pol-new/upload":
+			raw, _ := io.ReadAll(r.Body)
+			uploaded = string(raw)
+			uploadType = r.Header.Get("Content-Type")
+			w.WriteHeader(200)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	file := filepath.Join(t.TempDir(), "deny.rego")
+	os.WriteFile(file, []byte("package terraform.policies\n"), 0o644)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"policy", "create", "--org", "test-org", "deny-public", "--kind", "opa",
+		"--enforcement", "mandatory", "--query", "data.terraform.policies.deny", "--file", file, "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	attrs := createBody["data"].(map[string]interface{})["attributes"].(map[string]interface{})
+	if attrs["kind"] != "opa" || attrs["query"] != "data.terraform.policies.deny" || attrs["enforcement-level"] != "mandatory" {
+		t.Errorf("unexpected create attributes: %v", attrs)
+	}
+	if uploaded != "package terraform.policies\n" {
+		t.Errorf("expected policy code to be uploaded, got %q", uploaded)
+	}
+	if uploadType != "application/octet-stream" {
+		t.Errorf("expected octet-stream upload, got %q", uploadType)
+	}
+}
+
+func TestPolicyCreate_UploadFailureDeletesPolicy(t *testing.T) {
+	deleted := false
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v2/organizations/test-org/policies":
+			w.WriteHeader(201)
+			w.Write([]byte(`{"data":{"id":"pol-new","type":"policies","attributes":{"name":"deny-public","kind":"sentinel"}}}`))
+		case r.Method == "PUT" && r.URL.Path == "/api/v2/policies/pol-new/upload":
+			w.WriteHeader(500)
+		case r.Method == "DELETE" && r.URL.Path == "/api/v2/policies/pol-new":
+			deleted = true
+			w.WriteHeader(204)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	file := filepath.Join(t.TempDir(), "deny.sentinel")
+	os.WriteFile(file, []byte("main = rule { true }\n"), 0o644)
+
+	rootCmd.SetArgs([]string{"policy", "create", "--org", "test-org", "deny-public", "--kind", "sentinel",
+		"--enforcement", "advisory", "--query", "", "--file", file, "--json=false"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "pol-new") {
+		t.Fatalf("expected an upload error naming the policy, got %v", err)
+	}
+	if !deleted {
+		t.Error("expected the empty policy to be deleted")
+	}
+}
+
+func TestPolicyShow_CodeHonorsOutputFile(t *testing.T) {
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" && r.URL.Path == "/api/v2/policies/pol-1/download" {
+			w.Write([]byte("main = rule { true }\n"))
+			return
+		}
+		w.WriteHeader(404)
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	out := filepath.Join(t.TempDir(), "policy.sentinel")
+	defer func() { flagOutputFile = ""; policyShowCmd.Flags().Set("code", "false") }()
+	rootCmd.SetArgs([]string{"policy", "show", "pol-1", "--code", "-o", out})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "main = rule { true }\n" {
+		t.Errorf("unexpected policy code %q", data)
+	}
+}
+
+func TestPolicyUpdate_RejectsQueryForSentinel(t *testing.T) {
+	patched := false
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/policies/pol-1":
+			w.Write([]byte(`{"data":{"id":"pol-1","type":"policies","attributes":{"name":"deny-public","kind":"sentinel"}}}`))
+		case r.Method == "PATCH":
+			patched = true
+			w.Write([]byte(`{"data":{"id":"pol-1","type":"policies"}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+	defer func() { policyUpdateCmd.Flags().Lookup("query").Changed = false }()
+
+	rootCmd.SetArgs([]string{"policy", "update", "pol-1", "--query", "data.terraform.deny", "--json=false"})
+	err := rootCmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "only used with opa") {
+		t.Fatalf("expected --query to be rejected for a sentinel policy, got %v", err)
+	}
+	if patched {
+		t.Error("policy should not be updated")
+	}
+}
@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
 	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
 	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
 	"github.com/spf13/cobra"
@@ -19,8 +24,8 @@ var projectListCmd = &cobra.Command{
 }
 
 var projectShowCmd = &cobra.Command{
-	Use:   "show [id]",
-	Short: "Show project details",
+	Use:   "show [project]",
+	Short: "Show project details, variable sets and team access",
 	Args:  cobra.ExactArgs(1),
 	RunE:  runProjectShow,
 }
@@ -29,27 +34,32 @@ var projectCreateCmd = &cobra.Command{
 	Use:   "create [name]",
 	Short: "Create a new project",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("project create")
-	},
+	RunE:  runProjectCreate,
 }
 
 var projectUpdateCmd = &cobra.Command{
-	Use:   "update [id]",
+	Use:   "update [project]",
 	Short: "Update a project",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("project update")
-	},
+	RunE:  runProjectUpdate,
 }
 
 var projectDeleteCmd = &cobra.Command{
-	Use:   "delete [id]",
-	Short: "Delete a project",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("project delete")
-	},
+	Use:   "delete [project]",
+	Short: "Delete an empty project",
+	Long: `Delete a project.
+
+The project must not contain any workspaces; move them elsewhere first with
+"tfc project move-workspaces".`,
+	Args: cobra.ExactArgs(1),
+	RunE: runProjectDelete,
+}
+
+var projectMoveWorkspacesCmd = &cobra.Command{
+	Use:   "move-workspaces [workspace...]",
+	Short: "Move workspaces into a project",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runProjectMoveWorkspaces,
 }
 
 func init() {
@@ -58,12 +68,15 @@ func init() {
 	projectUpdateCmd.Flags().String("name", "", "New name")
 	projectUpdateCmd.Flags().String("description", "", "Description")
 
+	projectMoveWorkspacesCmd.Flags().String("to", "", "Destination project name or ID (required)")
+
 	projectCmd.AddCommand(
 		projectListCmd,
 		projectShowCmd,
 		projectCreateCmd,
 		projectUpdateCmd,
 		projectDeleteCmd,
+		projectMoveWorkspacesCmd,
 	)
 	rootCmd.AddCommand(projectCmd)
 }
@@ -122,8 +135,11 @@ func runProjectShow(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	projectID, err := resolveProjectID(args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
 
-	projectID := args[0]
 	var doc jsonapi.Document
 	if err := client.Get("/projects/"+projectID, &doc); err != nil {
 		return output.NewAPIError(err.Error())
@@ -137,6 +153,278 @@ func runProjectShow(cmd *cobra.Command, args []string) error {
 	var a projectAttrs
 	jsonapi.UnmarshalAttributes(res, &a)
 
+	workspaceCount, err := countProjectWorkspaces(client, extractRelationshipID(res, "organization"), projectID)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	type projVarset struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Global bool   `json:"global"`
+	}
+	var varsets []projVarset
+	if err := client.GetAllPages("/projects/"+projectID+"/varsets?page[size]=100", func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var va varsetAttrs
+			jsonapi.UnmarshalAttributes(&r, &va)
+			varsets = append(varsets, projVarset{ID: r.ID, Name: va.Name, Global: va.Global})
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	type projTeamAccess struct {
+		ID     string `json:"id"`
+		TeamID string `json:"team_id"`
+		Team   string `json:"team"`
+		Access string `json:"access"`
+	}
+	var teamAccess []projTeamAccess
+	path := fmt.Sprintf("/team-projects?filter[project][id]=%s&include=team", projectID)
+	if err := client.GetAllDocuments(path, func(d *jsonapi.Document) error {
+		resources, err := jsonapi.ParseList(d)
+		if err != nil {
+			return err
+		}
+		teamNames := map[string]string{}
+		for _, inc := range d.Included {
+			var ta teamAttrs
+			jsonapi.UnmarshalAttributes(&inc, &ta)
+			teamNames[inc.ID] = ta.Name
+		}
+		for _, r := range resources {
+			var tpa struct {
+				Access string `json:"access"`
+			}
+			jsonapi.UnmarshalAttributes(&r, &tpa)
+			teamID := extractRelationshipID(&r, "team")
+			teamAccess = append(teamAccess, projTeamAccess{
+				ID: r.ID, TeamID: teamID, Team: defaultStr(teamNames[teamID], teamID), Access: tpa.Access,
+			})
+		}
+		return nil
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	opts := GetOutputOptions()
+
+	type projDetail struct {
+		ID             string           `json:"id"`
+		Attrs          projectAttrs     `json:"attributes"`
+		WorkspaceCount int              `json:"workspace_count"`
+		Varsets        []projVarset     `json:"varsets"`
+		TeamAccess     []projTeamAccess `json:"team_access"`
+	}
+	data := projDetail{ID: res.ID, Attrs: a, WorkspaceCount: workspaceCount, Varsets: varsets, TeamAccess: teamAccess}
+
+	var varsetNames, accessDescs []string
+	for _, v := range varsets {
+		varsetNames = append(varsetNames, v.Name)
+	}
+	for _, t := range teamAccess {
+		accessDescs = append(accessDescs, fmt.Sprintf("%s (%s)", t.Team, t.Access))
+	}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Name", a.Name},
+			{"Description", defaultStr(a.Description, "-")},
+			{"Workspaces", itoa(workspaceCount)},
+			{"Variable Sets", defaultStr(joinTags(varsetNames), "-")},
+			{"Team Access", defaultStr(joinTags(accessDescs), "-")},
+			{"Created", a.CreatedAt},
+		},
+	}
+
+	return output.RenderTable(td, data, opts)
+}
+
+func runProjectCreate(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	description, _ := cmd.Flags().GetString("description")
+
+	attrs := map[string]interface{}{"name": args[0]}
+	if description != "" {
+		attrs["description"] = description
+	}
+
+	body, err := jsonapi.WrapForCreate("projects", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post("/organizations/"+org+"/projects", body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderProjectResult(cmd, &doc, "created")
+}
+
+func runProjectUpdate(cmd *cobra.Command, args []string) error {
+	attrs := map[string]interface{}{}
+	for _, name := range []string{"name", "description"} {
+		if cmd.Flags().Changed(name) {
+			v, _ := cmd.Flags().GetString(name)
+			attrs[name] = v
+		}
+	}
+	if len(attrs) == 0 {
+		return output.NewUsageError("nothing to update: pass --name or --description")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	projectID, err := resolveProjectID(args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	body, err := jsonapi.WrapForUpdate(projectID, "projects", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Patch("/projects/"+projectID, body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderProjectResult(cmd, &doc, "updated")
+}
+
+func runProjectDelete(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	projectID, err := resolveProjectID(args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	org, err := projectOrganization(client, projectID)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	count, err := countProjectWorkspaces(client, org, projectID)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	if count > 0 {
+		return output.NewUsageError(fmt.Sprintf("project %s still contains %d workspace(s); move them first with \"tfc project move-workspaces --to OTHER ...\"", projectID, count))
+	}
+
+	if err := client.Delete("/projects/" + projectID); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Project %s deleted\n", projectID)
+	return nil
+}
+
+func runProjectMoveWorkspaces(cmd *cobra.Command, args []string) error {
+	to, _ := cmd.Flags().GetString("to")
+	if to == "" {
+		return output.NewUsageError("--to is required")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	projectID, err := resolveProjectID(to)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var failed []string
+	for _, ws := range args {
+		wsID, err := resolveWorkspaceID(ws)
+		if err == nil {
+			body := map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   wsID,
+					"type": "workspaces",
+					"relationships": map[string]interface{}{
+						"project": map[string]interface{}{
+							"data": map[string]string{"type": "projects", "id": projectID},
+						},
+					},
+				},
+			}
+			err = client.Patch("/workspaces/"+wsID, body, nil)
+		}
+		if err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Failed to move %s: %v\n", ws, err)
+			failed = append(failed, ws)
+			continue
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Moved %s to project %s\n", ws, projectID)
+	}
+
+	if len(failed) > 0 {
+		return output.NewAPIError(fmt.Sprintf("failed to move %d of %d workspace(s): %s", len(failed), len(args), joinTags(failed)))
+	}
+	return nil
+}
+
+// projectOrganization returns the name of the organization a project
+// belongs to.
+func projectOrganization(client *api.Client, projectID string) (string, error) {
+	var doc jsonapi.Document
+	if err := client.Get("/projects/"+projectID, &doc); err != nil {
+		return "", err
+	}
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return "", err
+	}
+	return extractRelationshipID(res, "organization"), nil
+}
+
+// countProjectWorkspaces returns the number of workspaces in a project.
+func countProjectWorkspaces(client *api.Client, org, projectID string) (int, error) {
+	var doc jsonapi.Document
+	path := fmt.Sprintf("/organizations/%s/workspaces?filter[project][id]=%s&page[size]=1", org, projectID)
+	if err := client.Get(path, &doc); err != nil {
+		return 0, err
+	}
+	if doc.Meta != nil && doc.Meta.Pagination != nil {
+		return doc.Meta.Pagination.TotalCount, nil
+	}
+	resources, err := jsonapi.ParseList(&doc)
+	if err != nil {
+		return 0, err
+	}
+	return len(resources), nil
+}
+
+func renderProjectResult(cmd *cobra.Command, doc *jsonapi.Document, verb string) error {
+	res, err := jsonapi.ParseSingle(doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a projectAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
 	opts := GetOutputOptions()
 
 	type projDetail struct {
@@ -155,5 +443,42 @@ func runProjectShow(cmd *cobra.Command, args []string) error {
 		},
 	}
 
+	fmt.Fprintf(cmd.ErrOrStderr(), "Project %s %s\n", res.ID, verb)
 	return output.RenderTable(td, data, opts)
 }
+
+// resolveProjectID resolves a project name or ID to a project ID.
+// If the value starts with "prj-", it is returned as-is. Otherwise, it is
+// looked up by name using the organization from --org / TFC_ORG.
+func resolveProjectID(project string) (string, error) {
+	if strings.HasPrefix(project, "prj-") {
+		return project, nil
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return "", err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return "", err
+	}
+
+	var doc jsonapi.Document
+	path := fmt.Sprintf("/organizations/%s/projects?filter[names]=%s", org, url.QueryEscape(project))
+	if err := client.Get(path, &doc); err != nil {
+		return "", fmt.Errorf("resolve project %q: %w", project, err)
+	}
+	resources, err := jsonapi.ParseList(&doc)
+	if err != nil {
+		return "", fmt.Errorf("resolve project %q: %w", project, err)
+	}
+	for _, r := range resources {
+		var a projectAttrs
+		jsonapi.UnmarshalAttributes(&r, &a)
+		if a.Name == project {
+			return r.ID, nil
+		}
+	}
+	return "", fmt.Errorf("resolve project %q: not found in organization %s", project, org)
+}
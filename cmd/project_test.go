@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestProjectDelete_RefusesNonEmpty(t *testing.T) {
+	deleted := false
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/projects/prj-full":
+			w.Write([]byte(`{"data":{"id":"prj-full","type":"projects","attributes":{"name":"full"},
+				"relationships":{"organization":{"data":{"id":"test-org","type":"organizations"}}}}}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/workspaces":
+			if got := r.URL.Query().Get("filter[project][id]"); got != "prj-full" {
+				t.Errorf("expected project filter prj-full, got %q", got)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{map[string]interface{}{"id": "ws-a", "type": "workspaces"}},
+				"meta": map[string]interface{}{"pagination": map[string]interface{}{"total-count": 3}},
+			})
+		case r.Method == "DELETE":
+			deleted = true
+			w.WriteHeader(204)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"project", "delete", "--org", "test-org", "prj-full"})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "3 workspace") {
+		t.Fatalf("expected refusal mentioning 3 workspaces, got %v", err)
+	}
+	if deleted {
+		t.Error("project should not be deleted while it contains workspaces")
+	}
+}
+
+func TestProjectDelete_ByIDWithoutOrg(t *testing.T) {
+	deleted := false
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/projects/prj-empty":
+			w.Write([]byte(`{"data":{"id":"prj-empty","type":"projects","attributes":{"name":"empty"},
+				"relationships":{"organization":{"data":{"id":"acme","type":"organizations"}}}}}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/acme/workspaces":
+			w.Write([]byte(`{"data":[],"meta":{"pagination":{"total-count":0}}}`))
+		case r.Method == "DELETE" && r.URL.Path == "/api/v2/projects/prj-empty":
+			deleted = true
+			w.WriteHeader(204)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+	t.Setenv("TFC_ORG", "")
+
+	oldOrg := flagOrg
+	defer func() { flagOrg = oldOrg }()
+	flagOrg = ""
+
+	rootCmd.SetArgs([]string{"project", "delete", "prj-empty"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !deleted {
+		t.Error("expected the project to be deleted")
+	}
+}
+
+func TestProjectMoveWorkspaces(t *testing.T) {
+	moved := map[string]string{}
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/projects":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"id": "prj-target", "type": "projects", "attributes": map[string]interface{}{"name": "target"}},
+				},
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/workspaces/app":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"id": "ws-app", "type": "workspaces"},
+			})
+		case r.Method == "PATCH" && strings.HasPrefix(r.URL.Path, "/api/v2/workspaces/"):
+			var body struct {
+				Data struct {
+					Relationships struct {
+						Project struct {
+							Data struct {
+								ID string `json:"id"`
+							} `json:"data"`
+						} `json:"project"`
+					} `json:"relationships"`
+				} `json:"data"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			moved[strings.TrimPrefix(r.URL.Path, "/api/v2/workspaces/")] = body.Data.Relationships.Project.Data.ID
+			w.WriteHeader(200)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"project", "move-workspaces", "--org", "test-org", "--to", "target", "app", "ws-other"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if moved["ws-app"] != "prj-target" || moved["ws-other"] != "prj-target" {
+		t.Errorf("expected both workspaces moved to prj-target, got %v", moved)
+	}
+}
+
+func TestProjectShow_IDWithoutOrg(t *testing.T) {
+	var countedOrg string
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/projects/prj-abc":
+			w.Write([]byte(`{"data":{"id":"prj-abc","type":"projects","attributes":{"name":"platform"},
+				"relationships":{"organization":{"data":{"id":"acme","type":"organizations"}}}}}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/acme/workspaces":
+			countedOrg = "acme"
+			w.Write([]byte(`{"data":[],"meta":{"pagination":{"total-count":2}}}`))
+		case r.Method == "GET":
+			w.Write([]byte(`{"data":[]}`))
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	oldOrg := flagOrg
+	defer func() { flagOrg = oldOrg }()
+	flagOrg = ""
+
+	rootCmd.SetArgs([]string{"project", "show", "prj-abc", "--json=false"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if countedOrg != "acme" {
+		t.Error("expected workspaces to be counted in the project's organization")
+	}
+}
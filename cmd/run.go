@@ -23,8 +23,19 @@ var runCmd = &cobra.Command{
 
 var runListCmd = &cobra.Command{
 	Use:   "list",
-	Short: "List runs for a workspace",
-	RunE:  runRunList,
+	Short: "List runs for a workspace or organization",
+	Long: `List runs for a workspace, or across the organization when --workspace is
+omitted. Organization listings include each run's workspace, age and who
+triggered it.
+
+--status-group filters by status group (pending, planning,
+needs-confirmation, applying); see "tfc queue" for every active run.
+
+--operation, --source, --user and --commit are filtered by the API. --branch,
+--since, --until, --has-changes and --search are applied locally, reading
+further pages until --page-size runs match. Without --since, only the newest
+1000 runs are searched.`,
+	RunE: runRunList,
 }
 
 var runShowCmd = &cobra.Command{
@@ -62,8 +73,18 @@ var runCancelCmd = &cobra.Command{
 }
 
 func init() {
-	runListCmd.Flags().StringVar(&flagRunWorkspace, "workspace", "", "Workspace ID (required)")
+	runListCmd.Flags().StringVar(&flagRunWorkspace, "workspace", "", "Workspace ID (default: all workspaces in the organization)")
 	runListCmd.Flags().StringVar(&flagRunStatus, "status", "", "Filter by status")
+	runListCmd.Flags().StringSlice("status-group", nil, "Filter by status group (repeatable)")
+	runListCmd.Flags().StringSlice("operation", nil, "Filter by operation: plan_only, plan_and_apply, save_plan, refresh_only, destroy, empty_apply")
+	runListCmd.Flags().StringSlice("source", nil, "Filter by source (e.g. tfe-api, tfe-ui, tfe-configuration-version)")
+	runListCmd.Flags().String("user", "", "Filter by the username that triggered the run")
+	runListCmd.Flags().String("commit", "", "Filter by commit SHA")
+	runListCmd.Flags().String("branch", "", "Filter by VCS branch")
+	runListCmd.Flags().String("since", "", "Only runs created after this timestamp (RFC3339) or duration ago (e.g. 7d)")
+	runListCmd.Flags().String("until", "", "Only runs created before this timestamp (RFC3339) or duration ago")
+	runListCmd.Flags().Bool("has-changes", false, "Only runs whose plan has changes (--has-changes=false for none)")
+	runListCmd.Flags().String("search", "", "Only runs whose message contains this text")
 	runListCmd.Flags().IntVar(&flagRunPageSize, "page-size", 20, "Results per page")
 
 	runCreateCmd.Flags().String("workspace", "", "Workspace name or ID (required)")
@@ -99,26 +120,45 @@ type runAttrs struct {
 }
 
 func runRunList(cmd *cobra.Command, args []string) error {
+	if flagRunWorkspace == "" {
+		return runOrgRunList(cmd)
+	}
+
+	statuses, err := runListStatuses(cmd)
+	if err != nil {
+		return err
+	}
+	filter, err := newRunListFilter(cmd)
+	if err != nil {
+		return err
+	}
+
 	client, err := newClient()
 	if err != nil {
 		return err
 	}
 
-	if flagRunWorkspace == "" {
-		return output.NewUsageError("--workspace is required")
+	var params []string
+	if include := filter.include(); len(include) > 0 {
+		params = append(params, "include="+strings.Join(include, ","))
+	}
+	if len(statuses) > 0 {
+		params = append(params, "filter[status]="+strings.Join(statuses, ","))
 	}
+	params = append(params, filter.params()...)
 
-	path := fmt.Sprintf("/workspaces/%s/runs?page[size]=%d", flagRunWorkspace, flagRunPageSize)
-	if flagRunStatus != "" {
-		path += "&filter[status]=" + flagRunStatus
+	path := fmt.Sprintf("/workspaces/%s/runs", flagRunWorkspace)
+	if len(params) > 0 {
+		path += "?" + strings.Join(params, "&")
 	}
 
-	var doc jsonapi.Document
-	if err := client.Get(path, &doc); err != nil {
+	doc, truncated, err := fetchRunList(client, path, filter, flagRunPageSize)
+	if err != nil {
 		return output.NewAPIError(err.Error())
 	}
+	warnRunListTruncated(cmd.ErrOrStderr(), truncated)
 
-	resources, err := jsonapi.ParseList(&doc)
+	resources, err := jsonapi.ParseList(doc)
 	if err != nil {
 		return output.NewAPIError(err.Error())
 	}
@@ -423,3 +463,24 @@ func extractRelationshipID(res *jsonapi.Resource, relName string) string {
 	}
 	return ""
 }
+
+// extractRelationshipIDs pulls the "id" of every entry in a to-many relationship.
+func extractRelationshipIDs(res *jsonapi.Resource, relName string) []string {
+	raw, ok := res.Relationships[relName]
+	if !ok {
+		return nil
+	}
+	var rel struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if json.Unmarshal(raw, &rel) != nil {
+		return nil
+	}
+	ids := make([]string, 0, len(rel.Data))
+	for _, d := range rel.Data {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// runOperations are the values the API accepts for filter[operation].
+var runOperations = []string{"plan_only", "plan_and_apply", "save_plan", "refresh_only", "destroy", "empty_apply"}
+
+// runListFilter holds the "run list" filters beyond --status. Operation,
+// source, user and commit are sent to the API; the rest are applied to the
+// runs it returns.
+type runListFilter struct {
+	Operations []string
+	Sources    []string
+	User       string
+	Commit     string
+
+	Branch     string
+	Search     string
+	Since      time.Time
+	Until      time.Time
+	HasChanges *bool
+}
+
+// newRunListFilter reads the run list filter flags.
+func newRunListFilter(cmd *cobra.Command) (runListFilter, error) {
+	var f runListFilter
+	f.Operations, _ = cmd.Flags().GetStringSlice("operation")
+	f.Sources, _ = cmd.Flags().GetStringSlice("source")
+	f.User, _ = cmd.Flags().GetString("user")
+	f.Commit, _ = cmd.Flags().GetString("commit")
+	f.Branch, _ = cmd.Flags().GetString("branch")
+	f.Search, _ = cmd.Flags().GetString("search")
+
+	for _, op := range f.Operations {
+		if !containsStr(runOperations, op) {
+			return f, output.NewUsageError(fmt.Sprintf("invalid --operation %q: must be one of %s", op, strings.Join(runOperations, ", ")))
+		}
+	}
+
+	now := time.Now()
+	for _, bound := range []struct {
+		name string
+		t    *time.Time
+	}{{"since", &f.Since}, {"until", &f.Until}} {
+		v, _ := cmd.Flags().GetString(bound.name)
+		if v == "" {
+			continue
+		}
+		ts, err := auditTrailSince(v, now)
+		if err != nil {
+			return f, output.NewUsageError(fmt.Sprintf("invalid --%s %q: want an RFC3339 timestamp or a duration like 24h or 7d", bound.name, v))
+		}
+		*bound.t, _ = time.Parse(time.RFC3339Nano, ts)
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && f.Until.Before(f.Since) {
+		return f, output.NewUsageError("--until must not be before --since")
+	}
+
+	if cmd.Flags().Changed("has-changes") {
+		v, _ := cmd.Flags().GetBool("has-changes")
+		f.HasChanges = &v
+	}
+	return f, nil
+}
+
+// params returns the server-side filter query parameters.
+func (f runListFilter) params() []string {
+	var q []string
+	if len(f.Operations) > 0 {
+		q = append(q, "filter[operation]="+url.QueryEscape(strings.Join(f.Operations, ",")))
+	}
+	if len(f.Sources) > 0 {
+		q = append(q, "filter[source]="+url.QueryEscape(strings.Join(f.Sources, ",")))
+	}
+	if f.User != "" {
+		q = append(q, "search[user]="+url.QueryEscape(f.User))
+	}
+	if f.Commit != "" {
+		q = append(q, "search[commit]="+url.QueryEscape(f.Commit))
+	}
+	return q
+}
+
+// include returns the related resources the client-side filters need.
+func (f runListFilter) include() []string {
+	if f.Branch != "" {
+		return []string{"configuration_version.ingress_attributes"}
+	}
+	return nil
+}
+
+// clientSide reports whether any filter is applied to the returned runs.
+func (f runListFilter) clientSide() bool {
+	return f.Branch != "" || f.Search != "" || !f.Since.IsZero() || !f.Until.IsZero() || f.HasChanges != nil
+}
+
+// match reports whether a run passes the client-side filters. included maps
+// IDs to the sideloaded resources of its page.
+func (f runListFilter) match(r *jsonapi.Resource, a runAttrs, included map[string]*jsonapi.Resource) bool {
+	if f.HasChanges != nil && a.HasChanges != *f.HasChanges {
+		return false
+	}
+	if f.Search != "" && !strings.Contains(strings.ToLower(a.Message), strings.ToLower(f.Search)) {
+		return false
+	}
+	if !f.Since.IsZero() || !f.Until.IsZero() {
+		created, err := time.Parse(time.RFC3339, a.CreatedAt)
+		if err != nil || (!f.Since.IsZero() && created.Before(f.Since)) || (!f.Until.IsZero() && created.After(f.Until)) {
+			return false
+		}
+	}
+	if f.Branch != "" && runBranch(r, included) != f.Branch {
+		return false
+	}
+	return true
+}
+
+// runBranch returns the VCS branch a run's configuration came from, through
+// its sideloaded configuration version and ingress attributes.
+func runBranch(r *jsonapi.Resource, included map[string]*jsonapi.Resource) string {
+	cv := included[extractRelationshipID(r, "configuration-version")]
+	if cv == nil {
+		return ""
+	}
+	ia := included[extractRelationshipID(cv, "ingress-attributes")]
+	if ia == nil {
+		return ""
+	}
+	var a struct {
+		Branch string `json:"branch"`
+	}
+	jsonapi.UnmarshalAttributes(ia, &a)
+	return a.Branch
+}
+
+var errEnoughRuns = errors.New("enough runs")
+
+// maxRunFilterPages bounds how many pages of 100 runs the client-side filters
+// read when --since does not bound the search.
+const maxRunFilterPages = 10
+
+// fetchRunList gets a page of runs from path, which must not set page[size].
+// Without client-side filters this is a single request for pageSize runs.
+// Otherwise pages of 100 are walked, newest first, until pageSize runs match,
+// the runs are older than --since or, without --since, maxRunFilterPages
+// pages have been read; the result then has no pagination. truncated
+// reports that older runs were left unread because of that page limit.
+func fetchRunList(client *api.Client, path string, f runListFilter, pageSize int) (*jsonapi.Document, bool, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	if !f.clientSide() {
+		var doc jsonapi.Document
+		if err := client.Get(fmt.Sprintf("%s%spage[size]=%d", path, sep, pageSize), &doc); err != nil {
+			return nil, false, err
+		}
+		return &doc, false, nil
+	}
+
+	pages, truncated := 0, false
+	var matches []jsonapi.Resource
+	var included []jsonapi.Resource
+	err := client.GetAllDocuments(path+sep+"page[size]=100", func(doc *jsonapi.Document) error {
+		resources, err := jsonapi.ParseList(doc)
+		if err != nil {
+			return err
+		}
+		byID := map[string]*jsonapi.Resource{}
+		for i := range doc.Included {
+			byID[doc.Included[i].ID] = &doc.Included[i]
+		}
+		included = append(included, doc.Included...)
+
+		for i := range resources {
+			r := &resources[i]
+			var a runAttrs
+			jsonapi.UnmarshalAttributes(r, &a)
+			if f.match(r, a, byID) {
+				matches = append(matches, *r)
+				if len(matches) == pageSize {
+					return errEnoughRuns
+				}
+			}
+			if !f.Since.IsZero() {
+				if created, err := time.Parse(time.RFC3339, a.CreatedAt); err == nil && created.Before(f.Since) {
+					return errEnoughRuns
+				}
+			}
+		}
+
+		pages++
+		if f.Since.IsZero() && pages == maxRunFilterPages {
+			truncated = doc.Meta != nil && doc.Meta.Pagination != nil && doc.Meta.Pagination.NextPage != 0
+			return errEnoughRuns
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errEnoughRuns) {
+		return nil, false, err
+	}
+
+	if matches == nil {
+		matches = []jsonapi.Resource{}
+	}
+	data, err := json.Marshal(matches)
+	if err != nil {
+		return nil, false, err
+	}
+	return &jsonapi.Document{Data: data, Included: included}, truncated, nil
+}
+
+// warnRunListTruncated tells the user when fetchRunList stopped at its page
+// limit before reaching the oldest runs.
+func warnRunListTruncated(w io.Writer, truncated bool) {
+	if truncated {
+		fmt.Fprintf(w, "Searched the newest %d runs; use --since to search further back\n", maxRunFilterPages*100)
+	}
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"github.com/spf13/pflag"
+)
+
+// resetRunListFlags restores the run list filter flags after a test, as flag
+// values otherwise carry over to the next Execute.
+func resetRunListFlags(t *testing.T) {
+	t.Cleanup(func() {
+		runListCmd.Flags().VisitAll(func(f *pflag.Flag) {
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				sv.Replace(nil)
+			} else {
+				f.Value.Set(f.DefValue)
+			}
+			f.Changed = false
+		})
+	})
+}
+
+func TestRunListFilter_Match(t *testing.T) {
+	yes := true
+	f := runListFilter{
+		Search:     "Bump",
+		Since:      time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Until:      time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC),
+		HasChanges: &yes,
+	}
+	tests := []struct {
+		name string
+		a    runAttrs
+		want bool
+	}{
+		{"match", runAttrs{Message: "bump provider", CreatedAt: "2024-05-10T00:00:00Z", HasChanges: true}, true},
+		{"no changes", runAttrs{Message: "bump provider", CreatedAt: "2024-05-10T00:00:00Z"}, false},
+		{"message", runAttrs{Message: "refactor", CreatedAt: "2024-05-10T00:00:00Z", HasChanges: true}, false},
+		{"too old", runAttrs{Message: "bump", CreatedAt: "2024-04-30T00:00:00Z", HasChanges: true}, false},
+		{"too new", runAttrs{Message: "bump", CreatedAt: "2024-06-01T00:00:00Z", HasChanges: true}, false},
+	}
+	for _, tt := range tests {
+		if got := f.match(&jsonapi.Resource{}, tt.a, nil); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRunList_Filters(t *testing.T) {
+	var queries []url.Values
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path != "/api/v2/workspaces/ws-1/runs" {
+			w.WriteHeader(404)
+			return
+		}
+		queries = append(queries, r.URL.Query())
+		if r.URL.Query().Get("page[number]") == "1" {
+			w.Write([]byte(`{"data":[
+				{"id":"run-main","type":"runs","attributes":{"status":"applied","message":"Deploy"},
+				 "relationships":{"configuration-version":{"data":{"id":"cv-1","type":"configuration-versions"}}}},
+				{"id":"run-feature","type":"runs","attributes":{"status":"applied","message":"Deploy"},
+				 "relationships":{"configuration-version":{"data":{"id":"cv-2","type":"configuration-versions"}}}}],
+				"included":[
+				 {"id":"cv-1","type":"configuration-versions","relationships":{"ingress-attributes":{"data":{"id":"ia-1","type":"ingress-attributes"}}}},
+				 {"id":"cv-2","type":"configuration-versions","relationships":{"ingress-attributes":{"data":{"id":"ia-2","type":"ingress-attributes"}}}},
+				 {"id":"ia-1","type":"ingress-attributes","attributes":{"branch":"main"}},
+				 {"id":"ia-2","type":"ingress-attributes","attributes":{"branch":"feature"}}],
+				"meta":{"pagination":{"current-page":1,"next-page":2,"total-pages":2}}}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"run-other","type":"runs","attributes":{"status":"applied","message":"Fix"},
+			"relationships":{"configuration-version":{"data":{"id":"cv-1","type":"configuration-versions"}}}}],
+			"included":[
+			 {"id":"cv-1","type":"configuration-versions","relationships":{"ingress-attributes":{"data":{"id":"ia-1","type":"ingress-attributes"}}}},
+			 {"id":"ia-1","type":"ingress-attributes","attributes":{"branch":"main"}}],
+			"meta":{"pagination":{"current-page":2,"total-pages":2}}}`))
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+	resetRunListFlags(t)
+
+	out := filepath.Join(t.TempDir(), "runs.json")
+	defer func() { flagJSON = false; flagOutputFile = "" }()
+	rootCmd.SetArgs([]string{"run", "list", "--workspace", "ws-1", "--operation", "plan_only,destroy", "--source", "tfe-api",
+		"--user", "alice", "--commit", "abc123", "--branch", "main", "--search", "deploy", "--json", "-o", out})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 pages to be read, got %d", len(queries))
+	}
+	q := queries[0]
+	want := map[string]string{
+		"filter[operation]": "plan_only,destroy",
+		"filter[source]":    "tfe-api",
+		"search[user]":      "alice",
+		"search[commit]":    "abc123",
+		"include":           "configuration_version.ingress_attributes",
+		"page[size]":        "100",
+	}
+	for k, v := range want {
+		if q.Get(k) != v {
+			t.Errorf("%s = %q, want %q", k, q.Get(k), v)
+		}
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var runs []struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &runs); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if len(runs) != 1 || runs[0].ID != "run-main" {
+		t.Errorf("unexpected runs: %+v", runs)
+	}
+}
+
+func TestRunList_InvalidOperation(t *testing.T) {
+	t.Setenv("TFC_TOKEN", "test-token")
+	resetRunListFlags(t)
+
+	rootCmd.SetArgs([]string{"run", "list", "--workspace", "ws-1", "--operation", "apply"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected an error for an invalid operation")
+	}
+}
+
+func TestFetchRunList_PageLimitWithoutSince(t *testing.T) {
+	pages := 0
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		pages++
+		n := pages
+		fmt.Fprintf(w, `{"data":[{"id":"run-%d","type":"runs","attributes":{"status":"applied","message":"Fix",
+			"created-at":"2024-05-01T00:00:00Z"}}],"meta":{"pagination":{"current-page":%d,"next-page":%d,"total-pages":50}}}`, n, n, n+1)
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+	client, err := newClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, truncated, err := fetchRunList(client, "/organizations/test-org/runs", runListFilter{Search: "deploy"}, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pages != maxRunFilterPages || !truncated {
+		t.Errorf("expected %d pages and a truncated result, got %d pages, truncated=%v", maxRunFilterPages, pages, truncated)
+	}
+
+	// --since bounds the search instead of the page limit.
+	pages = 0
+	since := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	_, truncated, err = fetchRunList(client, "/organizations/test-org/runs", runListFilter{Search: "deploy", Since: since}, 20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pages <= maxRunFilterPages || truncated {
+		t.Errorf("expected more than %d pages without truncation, got %d pages, truncated=%v", maxRunFilterPages, pages, truncated)
+	}
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var runPoliciesCmd = &cobra.Command{
+	Use:   "policies [run-id]",
+	Short: "Show policy results for a run",
+	Long: `Show policy results for a run.
+
+Finds the policy results whichever mechanism the run used: policy evaluations
+on task stages (OPA, and Sentinel in agent mode) or legacy Sentinel policy
+checks. Each policy is listed with its policy set, enforcement level, result
+and output.
+
+With --override, every soft-mandatory failure waiting for an override is
+overridden: task stages awaiting override (with --comment as the reason) and
+overridable policy checks.
+
+With --format junit or sarif, the results are written as a JUnit XML or SARIF
+report instead, so policy outcomes show up as test results or code scanning
+alerts in CI.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRunPolicies,
+}
+
+func init() {
+	runPoliciesCmd.Flags().Bool("override", false, "Override soft-mandatory failures")
+	runPoliciesCmd.Flags().String("comment", "", "Reason for the override")
+	runPoliciesCmd.Flags().String("format", "", policyFormatUsage)
+
+	runCmd.AddCommand(runPoliciesCmd)
+}
+
+// Policy result mechanisms.
+const (
+	policyMechanismEvaluation = "policy-evaluation"
+	policyMechanismCheck      = "policy-check"
+)
+
+// policyResult is one policy's result from either mechanism.
+type policyResult struct {
+	Mechanism   string `json:"mechanism"`
+	SourceID    string `json:"source_id"`
+	PolicySet   string `json:"policy_set"`
+	Policy      string `json:"policy"`
+	Kind        string `json:"kind"`
+	Enforcement string `json:"enforcement"`
+	Status      string `json:"status"`
+	Output      string `json:"output"`
+}
+
+// failed reports whether the policy did not pass.
+func (r policyResult) failed() bool {
+	return r.Status != "passed"
+}
+
+// policyCheckResults extracts per-policy results from a legacy Sentinel
+// policy check result.
+func policyCheckResults(checkID string, a policyCheckAttrs) []policyResult {
+	sentinel, _ := a.Result["sentinel"].(map[string]interface{})
+	data, _ := sentinel["data"].(map[string]interface{})
+
+	setNames := make([]string, 0, len(data))
+	for name := range data {
+		setNames = append(setNames, name)
+	}
+	sort.Strings(setNames)
+
+	var results []policyResult
+	for _, setName := range setNames {
+		set, _ := data[setName].(map[string]interface{})
+		canOverride, _ := set["can-override"].(bool)
+		policies, _ := set["policies"].([]interface{})
+		for _, p := range policies {
+			policy, _ := p.(map[string]interface{})
+			name, _ := policy["policy"].(string)
+			passed, _ := policy["result"].(bool)
+			allowedFailure, _ := policy["allowed-failure"].(bool)
+
+			enforcement := "hard-mandatory"
+			switch {
+			case allowedFailure:
+				enforcement = "advisory"
+			case canOverride:
+				enforcement = "soft-mandatory"
+			}
+
+			status := "passed"
+			if !passed {
+				status = "failed"
+			}
+			var out string
+			if e, ok := policy["error"].(string); ok && e != "" {
+				status = "errored"
+				out = e
+			}
+			if trace, ok := policy["trace"].(map[string]interface{}); ok && out == "" {
+				out, _ = trace["print"].(string)
+				if out == "" {
+					out, _ = trace["description"].(string)
+				}
+			}
+
+			results = append(results, policyResult{
+				Mechanism:   policyMechanismCheck,
+				SourceID:    checkID,
+				PolicySet:   setName,
+				Policy:      strings.TrimPrefix(name, setName+"/"),
+				Kind:        "sentinel",
+				Enforcement: enforcement,
+				Status:      status,
+				Output:      strings.TrimSpace(out),
+			})
+		}
+	}
+	return results
+}
+
+type policySetOutcomeAttrs struct {
+	PolicySetName string `json:"policy-set-name"`
+	Overridable   *bool  `json:"overridable"`
+	Error         string `json:"error"`
+	Outcomes      []struct {
+		PolicyName       string          `json:"policy_name"`
+		EnforcementLevel string          `json:"enforcement_level"`
+		Status           string          `json:"status"`
+		Description      string          `json:"description"`
+		Query            string          `json:"query"`
+		Output           json.RawMessage `json:"output"`
+	} `json:"outcomes"`
+}
+
+// policySetOutcomeResults converts a policy set outcome into per-policy results.
+func policySetOutcomeResults(evalID, kind string, a policySetOutcomeAttrs) []policyResult {
+	var results []policyResult
+	if a.Error != "" && len(a.Outcomes) == 0 {
+		results = append(results, policyResult{
+			Mechanism: policyMechanismEvaluation,
+			SourceID:  evalID,
+			PolicySet: a.PolicySetName,
+			Kind:      kind,
+			Status:    "errored",
+			Output:    a.Error,
+		})
+	}
+	for _, o := range a.Outcomes {
+		out := o.Description
+		if len(o.Output) > 0 && string(o.Output) != "null" && string(o.Output) != "[]" {
+			out = strings.TrimSpace(out + " " + string(o.Output))
+		}
+		results = append(results, policyResult{
+			Mechanism:   policyMechanismEvaluation,
+			SourceID:    evalID,
+			PolicySet:   a.PolicySetName,
+			Policy:      o.PolicyName,
+			Kind:        kind,
+			Enforcement: o.EnforcementLevel,
+			Status:      o.Status,
+			Output:      out,
+		})
+	}
+	return results
+}
+
+// runPolicyState is everything found about a run's policies.
+type runPolicyState struct {
+	Results []policyResult
+	// OverridableStages are task stages awaiting a policy override.
+	OverridableStages []string
+	// OverridableChecks are soft-failed policy checks that can be overridden.
+	OverridableChecks []string
+}
+
+// fetchRunPolicies collects policy results for a run from task stage policy
+// evaluations and legacy policy checks.
+func fetchRunPolicies(client *api.Client, runID string) (*runPolicyState, error) {
+	state := &runPolicyState{}
+
+	var stagesDoc jsonapi.Document
+	if err := client.Get("/runs/"+runID+"/task-stages?include=policy_evaluations", &stagesDoc); err != nil {
+		return nil, err
+	}
+	stages, err := jsonapi.ParseList(&stagesDoc)
+	if err != nil {
+		return nil, err
+	}
+	evalKinds := map[string]string{}
+	for _, inc := range stagesDoc.Included {
+		if inc.Type != "policy-evaluations" {
+			continue
+		}
+		var ea struct {
+			PolicyKind string `json:"policy-kind"`
+		}
+		jsonapi.UnmarshalAttributes(&inc, &ea)
+		evalKinds[inc.ID] = ea.PolicyKind
+	}
+	for _, s := range stages {
+		var sa taskStageAttrs
+		jsonapi.UnmarshalAttributes(&s, &sa)
+		evalIDs := extractRelationshipIDs(&s, "policy-evaluations")
+		if sa.Status == "awaiting_override" && len(evalIDs) > 0 {
+			state.OverridableStages = append(state.OverridableStages, s.ID)
+		}
+		for _, evalID := range evalIDs {
+			path := fmt.Sprintf("/policy-evaluations/%s/policy-set-outcomes?page[size]=100", evalID)
+			if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+				for _, r := range page {
+					var oa policySetOutcomeAttrs
+					jsonapi.UnmarshalAttributes(&r, &oa)
+					state.Results = append(state.Results, policySetOutcomeResults(evalID, evalKinds[evalID], oa)...)
+				}
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var checksDoc jsonapi.Document
+	if err := client.Get("/runs/"+runID+"/policy-checks", &checksDoc); err != nil {
+		return nil, err
+	}
+	checks, err := jsonapi.ParseList(&checksDoc)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range checks {
+		var ca policyCheckAttrs
+		jsonapi.UnmarshalAttributes(&c, &ca)
+		if ca.Actions["is-overridable"] && ca.Status == "soft_failed" {
+			state.OverridableChecks = append(state.OverridableChecks, c.ID)
+		}
+		state.Results = append(state.Results, policyCheckResults(c.ID, ca)...)
+	}
+
+	return state, nil
+}
+
+func runRunPolicies(cmd *cobra.Command, args []string) error {
+	override, _ := cmd.Flags().GetBool("override")
+	comment, _ := cmd.Flags().GetString("comment")
+	format, _ := cmd.Flags().GetString("format")
+	if err := validatePolicyFormat(format); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	runID := args[0]
+	state, err := fetchRunPolicies(client, runID)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	stderr := cmd.ErrOrStderr()
+	if override {
+		if len(state.OverridableStages) == 0 && len(state.OverridableChecks) == 0 {
+			return output.NewUsageError(fmt.Sprintf("run %s has no policy failures awaiting override", runID))
+		}
+		for _, stageID := range state.OverridableStages {
+			var body interface{}
+			if comment != "" {
+				body = map[string]string{"comment": comment}
+			}
+			if err := client.Post("/task-stages/"+stageID+"/actions/override", body, nil); err != nil {
+				return output.NewAPIError(fmt.Sprintf("override task stage %s: %v", stageID, err))
+			}
+			fmt.Fprintf(stderr, "Task stage %s overridden\n", stageID)
+		}
+		for _, checkID := range state.OverridableChecks {
+			if err := client.Post("/policy-checks/"+checkID+"/actions/override", nil, nil); err != nil {
+				return output.NewAPIError(fmt.Sprintf("override policy check %s: %v", checkID, err))
+			}
+			fmt.Fprintf(stderr, "Policy check %s overridden\n", checkID)
+		}
+		if state, err = fetchRunPolicies(client, runID); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	}
+
+	td := output.TableData{
+		Headers: []string{"POLICY SET", "POLICY", "KIND", "ENFORCEMENT", "STATUS", "OUTPUT"},
+	}
+	failed := 0
+	for _, r := range state.Results {
+		if r.failed() {
+			failed++
+		}
+		td.Rows = append(td.Rows, []string{
+			r.PolicySet, defaultStr(r.Policy, "-"), defaultStr(r.Kind, "-"), defaultStr(r.Enforcement, "-"),
+			r.Status, truncateStr(defaultStr(strings.ReplaceAll(r.Output, "\n", " "), "-"), 60),
+		})
+	}
+
+	if len(state.Results) == 0 {
+		fmt.Fprintf(stderr, "No policy results found for run %s\n", runID)
+	} else {
+		fmt.Fprintf(stderr, "%d policies, %d not passed\n", len(state.Results), failed)
+	}
+	if !override && (len(state.OverridableStages) > 0 || len(state.OverridableChecks) > 0) {
+		fmt.Fprintf(stderr, "Soft-mandatory failures are awaiting override; rerun with --override to continue the run\n")
+	}
+
+	if format != "" {
+		return renderPolicyReport(format, runID, state.Results)
+	}
+	return output.RenderTable(td, state.Results, GetOutputOptions())
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// testPolicyCheckResult is a legacy Sentinel policy check result with one
+// policy set holding a passing advisory policy and a failing soft-mandatory one.
+const testPolicyCheckResult = `{
+  "result": false,
+  "sentinel": {
+    "schema-version": "1.0.0",
+    "data": {
+      "networking": {
+        "can-override": true,
+        "result": false,
+        "policies": [
+          {"policy": "networking/restrict-cidr", "result": false, "allowed-failure": false,
+           "trace": {"print": "0.0.0.0/0 is not allowed\n", "description": "Restrict ingress"}},
+          {"policy": "networking/tags", "result": true, "allowed-failure": true, "trace": {"print": ""}}
+        ]
+      }
+    }
+  }
+}`
+
+func TestPolicyCheckResults(t *testing.T) {
+	var a policyCheckAttrs
+	if err := json.Unmarshal([]byte(`{"status":"soft_failed","result":`+testPolicyCheckResult+`}`), &a); err != nil {
+		t.Fatal(err)
+	}
+
+	results := policyCheckResults("polchk-1", a)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	r := results[0]
+	if r.PolicySet != "networking" || r.Policy != "restrict-cidr" || r.Status != "failed" ||
+		r.Enforcement != "soft-mandatory" || r.Output != "0.0.0.0/0 is not allowed" {
+		t.Errorf("unexpected failing result: %+v", r)
+	}
+	if results[1].Status != "passed" || results[1].Enforcement != "advisory" || results[1].Output != "" {
+		t.Errorf("unexpected advisory result: %+v", results[1])
+	}
+}
+
+func TestPolicySetOutcomeResults(t *testing.T) {
+	var a policySetOutcomeAttrs
+	json.Unmarshal([]byte(`{
+		"policy-set-name": "opa-baseline",
+		"outcomes": [
+			{"policy_name": "deny-public", "enforcement_level": "mandatory", "status": "failed",
+			 "description": "No public buckets", "output": [{"bucket": "logs"}]},
+			{"policy_name": "tags", "enforcement_level": "advisory", "status": "passed"}
+		]
+	}`), &a)
+
+	results := policySetOutcomeResults("poleval-1", "opa", a)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if results[0].Policy != "deny-public" || results[0].Kind != "opa" || !results[0].failed() ||
+		results[0].Output != `No public buckets [{"bucket": "logs"}]` {
+		t.Errorf("unexpected failing result: %+v", results[0])
+	}
+	if results[1].failed() {
+		t.Errorf("expected passing result, got %+v", results[1])
+	}
+}
+
+func TestRunPolicies_OverrideTaskStage(t *testing.T) {
+	var overrideBody map[string]string
+	overridden := false
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/runs/run-abc/task-stages":
+			status := "awaiting_override"
+			if overridden {
+				status = "overridden"
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{
+						"id": "ts-post", "type": "task-stages",
+						"attributes": map[string]interface{}{"stage": "post-plan", "status": status},
+						"relationships": map[string]interface{}{
+							"policy-evaluations": map[string]interface{}{
+								"data": []interface{}{map[string]interface{}{"type": "policy-evaluations", "id": "poleval-1"}},
+							},
+						},
+					},
+				},
+				"included": []interface{}{
+					map[string]interface{}{"id": "poleval-1", "type": "policy-evaluations",
+						"attributes": map[string]interface{}{"policy-kind": "opa", "status": "failed"}},
+				},
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/v2/policy-evaluations/poleval-1/policy-set-outcomes":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"id": "psout-1", "type": "policy-set-outcomes",
+						"attributes": map[string]interface{}{
+							"policy-set-name": "baseline",
+							"outcomes": []interface{}{
+								map[string]interface{}{"policy_name": "deny-public", "enforcement_level": "mandatory", "status": "failed"},
+							},
+						}},
+				},
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/v2/runs/run-abc/policy-checks":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": []interface{}{}})
+		case r.Method == "POST" && r.URL.Path == "/api/v2/task-stages/ts-post/actions/override":
+			json.NewDecoder(r.Body).Decode(&overrideBody)
+			overridden = true
+			w.WriteHeader(200)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"run", "policies", "run-abc", "--override", "--comment", "approved by sec", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !overridden || overrideBody["comment"] != "approved by sec" {
+		t.Errorf("expected task stage override with comment, got overridden=%v body=%v", overridden, overrideBody)
+	}
+}
@@ -0,0 +1,225 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var runPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Discard or cancel stale runs across the organization",
+	Long: `Discard or cancel stale runs across the organization.
+
+Finds active runs older than --older-than with one of the --status statuses
+(by default, runs waiting for confirmation) and lists them. With --yes, runs
+that are queued or waiting for confirmation are discarded and runs that are
+planning or applying are cancelled, --concurrency at a time, and the result
+of each is reported.`,
+	RunE: runRunPrune,
+}
+
+func init() {
+	runPruneCmd.Flags().String("older-than", "", "Only runs created longer ago than this (e.g. 12h, 7d) (required)")
+	runPruneCmd.Flags().StringSlice("status", runStatusGroups["needs-confirmation"], "Run statuses to prune")
+	runPruneCmd.Flags().StringSlice("workspace-tag", nil, "Only workspaces with this tag (repeatable; all must match)")
+	runPruneCmd.Flags().String("comment", "", "Comment for discarded runs")
+	runPruneCmd.Flags().Int("concurrency", 4, "Runs to discard or cancel at a time")
+	runPruneCmd.Flags().Bool("yes", false, "Discard or cancel the runs (default: only list them)")
+
+	runCmd.AddCommand(runPruneCmd)
+}
+
+// pruneAction returns how a run with the status is pruned: "discard" while
+// it waits in the queue or for confirmation, "cancel" while it is planning
+// or applying, or "" if it is final.
+func pruneAction(status string) string {
+	switch runStatusGroup(status) {
+	case "":
+		return ""
+	case "pending", "needs-confirmation":
+		return "discard"
+	default:
+		return "cancel"
+	}
+}
+
+// pruneResult is the outcome of pruning one run.
+type pruneResult struct {
+	ID        string `json:"id"`
+	Workspace string `json:"workspace"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created_at"`
+	Action    string `json:"action"`
+	Result    string `json:"result"`
+	Error     string `json:"error,omitempty"`
+}
+
+// taggedWorkspaceIDs returns the IDs of the organization's workspaces that
+// have all the tags.
+func taggedWorkspaceIDs(client *api.Client, org string, tags []string) (map[string]bool, error) {
+	ids := map[string]bool{}
+	path := fmt.Sprintf("/organizations/%s/workspaces?page[size]=100&search[tags]=%s", org, url.QueryEscape(strings.Join(tags, ",")))
+	err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+		for _, r := range page {
+			ids[r.ID] = true
+		}
+	})
+	return ids, err
+}
+
+// pruneRuns discards or cancels runs, at most concurrency at a time, and
+// fills in each result.
+func pruneRuns(client *api.Client, results []pruneResult, comment string, concurrency int) {
+	var body interface{}
+	if comment != "" {
+		body = map[string]string{"comment": comment}
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(r *pruneResult) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var err error
+			if r.Action == "discard" {
+				err = client.Post(fmt.Sprintf("/runs/%s/actions/discard", r.ID), body, nil)
+			} else {
+				err = client.Post(fmt.Sprintf("/runs/%s/actions/cancel", r.ID), nil, nil)
+			}
+			if err != nil {
+				r.Result = "failed"
+				r.Error = err.Error()
+				return
+			}
+			if r.Action == "discard" {
+				r.Result = "discarded"
+			} else {
+				r.Result = "cancelled"
+			}
+		}(&results[i])
+	}
+	wg.Wait()
+}
+
+func runRunPrune(cmd *cobra.Command, args []string) error {
+	olderThan, _ := cmd.Flags().GetString("older-than")
+	statuses, _ := cmd.Flags().GetStringSlice("status")
+	tags, _ := cmd.Flags().GetStringSlice("workspace-tag")
+	comment, _ := cmd.Flags().GetString("comment")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	yes, _ := cmd.Flags().GetBool("yes")
+
+	if olderThan == "" {
+		return output.NewUsageError("--older-than is required")
+	}
+	age, err := parseDuration(olderThan)
+	if err != nil || age <= 0 {
+		return output.NewUsageError(fmt.Sprintf("invalid --older-than %q: want a duration like 12h or 7d", olderThan))
+	}
+	if len(statuses) == 0 {
+		return output.NewUsageError("--status must name at least one status")
+	}
+	for _, s := range statuses {
+		if pruneAction(s) == "" {
+			return output.NewUsageError(fmt.Sprintf("status %q is not an active run status", s))
+		}
+	}
+	if concurrency < 1 {
+		return output.NewUsageError("--concurrency must be at least 1")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	runs, err := fetchOrgRuns(client, org, statuses)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var workspaces map[string]bool
+	if len(tags) > 0 {
+		if workspaces, err = taggedWorkspaceIDs(client, org, tags); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	}
+
+	cutoff := time.Now().Add(-age)
+	var results []pruneResult
+	for _, r := range runs {
+		created, err := time.Parse(time.RFC3339, r.Attrs.CreatedAt)
+		if err != nil || !created.Before(cutoff) || !containsStr(statuses, r.Attrs.Status) {
+			continue
+		}
+		if workspaces != nil && !workspaces[r.WorkspaceID] {
+			continue
+		}
+		results = append(results, pruneResult{
+			ID:        r.ID,
+			Workspace: r.Workspace,
+			Status:    r.Attrs.Status,
+			CreatedAt: r.Attrs.CreatedAt,
+			Action:    pruneAction(r.Attrs.Status),
+			Result:    "dry-run",
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].CreatedAt < results[j].CreatedAt })
+
+	stderr := cmd.ErrOrStderr()
+	failed := 0
+	if yes {
+		pruneRuns(client, results, comment, concurrency)
+		for _, r := range results {
+			if r.Result == "failed" {
+				failed++
+			}
+		}
+		fmt.Fprintf(stderr, "Pruned %d of %d runs older than %s\n", len(results)-failed, len(results), olderThan)
+	} else {
+		fmt.Fprintf(stderr, "%d runs older than %s would be pruned; rerun with --yes to discard or cancel them\n", len(results), olderThan)
+	}
+
+	opts := GetOutputOptions()
+	now := time.Now()
+	td := output.TableData{
+		Headers: []string{"WORKSPACE", "ID", "STATUS", "AGE", "ACTION", "RESULT"},
+	}
+	for _, r := range results {
+		result := r.Result
+		if r.Error != "" {
+			result += ": " + truncateStr(r.Error, 60)
+		}
+		td.Rows = append(td.Rows, []string{
+			r.Workspace, r.ID, r.Status, runAge(r.CreatedAt, now), r.Action, result,
+		})
+	}
+	if results == nil {
+		results = []pruneResult{}
+	}
+	if err := output.RenderTable(td, results, opts); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return output.NewAPIError(fmt.Sprintf("%d of %d runs could not be pruned", failed, len(results)))
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// resetPruneFlags clears the slice flags of run prune after a test; once
+// set, pflag slice values append rather than replace on the next Execute.
+func resetPruneFlags(t *testing.T) {
+	t.Cleanup(func() {
+		for _, name := range []string{"status", "workspace-tag"} {
+			runPruneCmd.Flags().Lookup(name).Value.(pflag.SliceValue).Replace(nil)
+		}
+	})
+}
+
+func TestRunPrune(t *testing.T) {
+	old := time.Now().Add(-10 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+
+	var mu sync.Mutex
+	var actions []string
+	var wsQuery string
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/runs":
+			w.Write([]byte(`{"data":[
+				{"id":"run-old","type":"runs","attributes":{"status":"planned","created-at":"` + old + `"},
+				 "relationships":{"workspace":{"data":{"id":"ws-1","type":"workspaces"}}}},
+				{"id":"run-broken","type":"runs","attributes":{"status":"policy_checked","created-at":"` + old + `"},
+				 "relationships":{"workspace":{"data":{"id":"ws-1","type":"workspaces"}}}},
+				{"id":"run-planning","type":"runs","attributes":{"status":"planning","created-at":"` + old + `"},
+				 "relationships":{"workspace":{"data":{"id":"ws-1","type":"workspaces"}}}},
+				{"id":"run-queued","type":"runs","attributes":{"status":"pending","created-at":"` + old + `"},
+				 "relationships":{"workspace":{"data":{"id":"ws-1","type":"workspaces"}}}},
+				{"id":"run-recent","type":"runs","attributes":{"status":"planned","created-at":"` + recent + `"},
+				 "relationships":{"workspace":{"data":{"id":"ws-1","type":"workspaces"}}}},
+				{"id":"run-untagged","type":"runs","attributes":{"status":"planned","created-at":"` + old + `"},
+				 "relationships":{"workspace":{"data":{"id":"ws-2","type":"workspaces"}}}}],
+				"included":[{"id":"ws-1","type":"workspaces","attributes":{"name":"app"}}]}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/workspaces":
+			wsQuery = r.URL.Query().Get("search[tags]")
+			w.Write([]byte(`{"data":[{"id":"ws-1","type":"workspaces","attributes":{"name":"app"}}]}`))
+		case r.Method == "POST" && r.URL.Path == "/api/v2/runs/run-broken/actions/discard":
+			w.WriteHeader(409)
+			w.Write([]byte(`{"errors":[{"status":"409","title":"transition not allowed"}]}`))
+		case r.Method == "POST":
+			mu.Lock()
+			actions = append(actions, r.URL.Path)
+			mu.Unlock()
+			w.WriteHeader(202)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+	resetPruneFlags(t)
+
+	out := filepath.Join(t.TempDir(), "prune.json")
+	defer func() { flagJSON = false; flagOutputFile = "" }()
+	rootCmd.SetArgs([]string{"run", "prune", "--org", "test-org", "--older-than", "7d",
+		"--status", "planned,policy_checked,planning,pending", "--workspace-tag", "prod", "--yes", "--json", "-o", out})
+	err := rootCmd.Execute()
+	if err == nil {
+		t.Fatal("expected an error for the failed discard")
+	}
+
+	if wsQuery != "prod" {
+		t.Errorf("expected tag search, got %q", wsQuery)
+	}
+	sort.Strings(actions)
+	wantActions := []string{
+		"/api/v2/runs/run-old/actions/discard",
+		"/api/v2/runs/run-planning/actions/cancel",
+		"/api/v2/runs/run-queued/actions/discard",
+	}
+	if len(actions) != len(wantActions) {
+		t.Fatalf("unexpected actions: %v", actions)
+	}
+	for i, want := range wantActions {
+		if actions[i] != want {
+			t.Errorf("action %d: got %s, want %s", i, actions[i], want)
+		}
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []pruneResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	got := map[string]string{}
+	for _, r := range results {
+		got[r.ID] = r.Result
+	}
+	want := map[string]string{"run-old": "discarded", "run-broken": "failed", "run-planning": "cancelled", "run-queued": "discarded"}
+	if len(got) != len(want) {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	for id, res := range want {
+		if got[id] != res {
+			t.Errorf("%s: got %q, want %q", id, got[id], res)
+		}
+	}
+}
+
+func TestRunPrune_DryRun(t *testing.T) {
+	old := time.Now().Add(-10 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.Method != "GET" {
+			t.Errorf("unexpected %s %s without --yes", r.Method, r.URL.Path)
+			w.WriteHeader(500)
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"run-old","type":"runs","attributes":{"status":"planned","created-at":"` + old + `"}}]}`))
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+	resetPruneFlags(t)
+
+	rootCmd.SetArgs([]string{"run", "prune", "--org", "test-org", "--older-than", "7d", "--status", "planned", "--yes=false", "--json=false"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRunPrune_RejectsFinalStatus(t *testing.T) {
+	t.Setenv("TFC_TOKEN", "test-token")
+	resetPruneFlags(t)
+
+	rootCmd.SetArgs([]string{"run", "prune", "--org", "test-org", "--older-than", "7d", "--status", "applied"})
+	if err := rootCmd.Execute(); err == nil {
+		t.Fatal("expected an error for a final status")
+	}
+}
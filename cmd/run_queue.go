@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var queueCmd = &cobra.Command{
+	Use:   "queue",
+	Short: "Show active runs across the organization",
+	Long: `Show active runs across the organization, grouped by status group:
+
+  pending             queued or running pre-plan tasks
+  planning            planning, cost estimation, policy checks, post-plan tasks
+  needs-confirmation  planned and waiting for someone to apply or discard
+  applying            confirmed, applying or running post-apply tasks
+
+Runs are listed by group and then oldest first, with their workspace, age
+and who triggered them.`,
+	RunE: runQueue,
+}
+
+func init() {
+	queueCmd.Flags().StringSlice("status-group", nil, "Only these status groups (repeatable; default all)")
+
+	rootCmd.AddCommand(queueCmd)
+}
+
+// runStatusGroupOrder is the order active runs move through the groups.
+var runStatusGroupOrder = []string{"pending", "planning", "needs-confirmation", "applying"}
+
+// runStatusGroups maps each status group to its run statuses.
+var runStatusGroups = map[string][]string{
+	"pending": {
+		"pending", "fetching", "fetching_completed", "pre_plan_running", "pre_plan_completed",
+		"queuing", "plan_queued",
+	},
+	"planning": {
+		"planning", "cost_estimating", "policy_checking", "post_plan_running", "post_plan_completed",
+	},
+	"needs-confirmation": {
+		"planned", "cost_estimated", "policy_checked", "policy_override", "policy_soft_failed",
+		"post_plan_awaiting_decision",
+	},
+	"applying": {
+		"confirmed", "pre_apply_running", "pre_apply_completed", "queuing_apply", "apply_queued", "applying",
+		"post_apply_running", "post_apply_completed",
+	},
+}
+
+// runStatusGroup returns the status group of a run status, or "" for
+// statuses that are final.
+func runStatusGroup(status string) string {
+	for _, group := range runStatusGroupOrder {
+		if containsStr(runStatusGroups[group], status) {
+			return group
+		}
+	}
+	return ""
+}
+
+// statusGroupStatuses returns the statuses in the given groups, checking the
+// group names.
+func statusGroupStatuses(groups []string) ([]string, error) {
+	var statuses []string
+	for _, g := range groups {
+		list, ok := runStatusGroups[g]
+		if !ok {
+			return nil, output.NewUsageError(fmt.Sprintf("invalid status group %q: must be one of %s", g, strings.Join(runStatusGroupOrder, ", ")))
+		}
+		statuses = append(statuses, list...)
+	}
+	return statuses, nil
+}
+
+// runListStatuses returns the statuses "run list" filters by: those in the
+// --status-group groups plus any given with --status.
+func runListStatuses(cmd *cobra.Command) ([]string, error) {
+	groups, _ := cmd.Flags().GetStringSlice("status-group")
+	statuses, err := statusGroupStatuses(groups)
+	if err != nil {
+		return nil, err
+	}
+	if flagRunStatus != "" {
+		statuses = append(statuses, strings.Split(flagRunStatus, ",")...)
+	}
+	return statuses, nil
+}
+
+// orgRun is a run listed across the organization.
+type orgRun struct {
+	ID          string   `json:"id"`
+	WorkspaceID string   `json:"workspace_id"`
+	Workspace   string   `json:"workspace"`
+	StatusGroup string   `json:"status_group"`
+	TriggeredBy string   `json:"triggered_by"`
+	Attrs       runAttrs `json:"attributes"`
+}
+
+// orgRunsPath is the organization runs endpoint with workspaces, the
+// triggering users and any extra resources sideloaded.
+func orgRunsPath(org string, statuses []string, include ...string) string {
+	include = append([]string{"workspace", "created_by"}, include...)
+	path := fmt.Sprintf("/organizations/%s/runs?include=%s", org, strings.Join(include, ","))
+	if len(statuses) > 0 {
+		path += "&filter[status]=" + url.QueryEscape(strings.Join(statuses, ","))
+	}
+	return path
+}
+
+// orgRunsFromDocument reads runs from an organization runs page, resolving
+// workspace names and triggering users from the included resources.
+func orgRunsFromDocument(doc *jsonapi.Document) ([]orgRun, error) {
+	resources, err := jsonapi.ParseList(doc)
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]string{}
+	for _, inc := range doc.Included {
+		var n struct {
+			Name     string `json:"name"`
+			Username string `json:"username"`
+		}
+		jsonapi.UnmarshalAttributes(&inc, &n)
+		names[inc.ID] = defaultStr(n.Name, n.Username)
+	}
+
+	var runs []orgRun
+	for _, r := range resources {
+		var a runAttrs
+		jsonapi.UnmarshalAttributes(&r, &a)
+		wsID := extractRelationshipID(&r, "workspace")
+		userID := extractRelationshipID(&r, "created-by")
+		runs = append(runs, orgRun{
+			ID:          r.ID,
+			WorkspaceID: wsID,
+			Workspace:   defaultStr(names[wsID], wsID),
+			StatusGroup: runStatusGroup(a.Status),
+			TriggeredBy: defaultStr(names[userID], userID),
+			Attrs:       a,
+		})
+	}
+	return runs, nil
+}
+
+// fetchOrgRuns collects every run in the organization with one of the
+// statuses.
+func fetchOrgRuns(client *api.Client, org string, statuses []string) ([]orgRun, error) {
+	var runs []orgRun
+	err := client.GetAllDocuments(orgRunsPath(org, statuses)+"&page[size]=100", func(doc *jsonapi.Document) error {
+		page, err := orgRunsFromDocument(doc)
+		runs = append(runs, page...)
+		return err
+	})
+	return runs, err
+}
+
+// runAge formats how long ago a run was created, e.g. 45s, 12m, 3h or 5d.
+func runAge(createdAt string, now time.Time) string {
+	t, err := time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return "-"
+	}
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// renderOrgRuns renders runs across the organization.
+func renderOrgRuns(runs []orgRun) error {
+	opts := GetOutputOptions()
+	now := time.Now()
+
+	td := output.TableData{
+		Headers: []string{"WORKSPACE", "ID", "STATUS", "GROUP", "AGE", "TRIGGERED BY", "MESSAGE"},
+	}
+	for _, r := range runs {
+		td.Rows = append(td.Rows, []string{
+			r.Workspace, r.ID, r.Attrs.Status, defaultStr(r.StatusGroup, "-"), runAge(r.Attrs.CreatedAt, now),
+			defaultStr(r.TriggeredBy, "-"), truncateStr(r.Attrs.Message, 40),
+		})
+	}
+	if runs == nil {
+		runs = []orgRun{}
+	}
+	return output.RenderTable(td, runs, opts)
+}
+
+// runOrgRunList lists one page of runs across the organization for
+// "run list" without --workspace.
+func runOrgRunList(cmd *cobra.Command) error {
+	statuses, err := runListStatuses(cmd)
+	if err != nil {
+		return err
+	}
+	filter, err := newRunListFilter(cmd)
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return output.NewUsageError("--workspace or an organization (--org or TFC_ORG) is required")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	path := orgRunsPath(org, statuses, filter.include()...)
+	for _, p := range filter.params() {
+		path += "&" + p
+	}
+	doc, truncated, err := fetchRunList(client, path, filter, flagRunPageSize)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	warnRunListTruncated(cmd.ErrOrStderr(), truncated)
+	runs, err := orgRunsFromDocument(doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if doc.Meta != nil && doc.Meta.Pagination != nil {
+		p := doc.Meta.Pagination
+		fmt.Fprintf(cmd.ErrOrStderr(), "Page %d/%d (%d total)\n", p.CurrentPage, p.TotalPages, p.TotalCount)
+	}
+
+	return renderOrgRuns(runs)
+}
+
+func runQueue(cmd *cobra.Command, args []string) error {
+	groups, _ := cmd.Flags().GetStringSlice("status-group")
+	if len(groups) == 0 {
+		groups = runStatusGroupOrder
+	}
+	statuses, err := statusGroupStatuses(groups)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	runs, err := fetchOrgRuns(client, org, statuses)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	// Keep only runs in the requested groups, in case the API returned others.
+	active := runs[:0]
+	for _, r := range runs {
+		if containsStr(groups, r.StatusGroup) {
+			active = append(active, r)
+		}
+	}
+	rank := map[string]int{}
+	for i, g := range runStatusGroupOrder {
+		rank[g] = i
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		if rank[active[i].StatusGroup] != rank[active[j].StatusGroup] {
+			return rank[active[i].StatusGroup] < rank[active[j].StatusGroup]
+		}
+		return active[i].Attrs.CreatedAt < active[j].Attrs.CreatedAt
+	})
+
+	counts := make([]string, 0, len(groups))
+	for _, g := range runStatusGroupOrder {
+		if !containsStr(groups, g) {
+			continue
+		}
+		n := 0
+		for _, r := range active {
+			if r.StatusGroup == g {
+				n++
+			}
+		}
+		counts = append(counts, fmt.Sprintf("%d %s", n, g))
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "%d active runs in %s: %s\n", len(active), org, strings.Join(counts, ", "))
+
+	return renderOrgRuns(active)
+}
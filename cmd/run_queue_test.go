@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunStatusGroup(t *testing.T) {
+	tests := map[string]string{
+		"plan_queued":          "pending",
+		"policy_checking":      "planning",
+		"policy_soft_failed":   "needs-confirmation",
+		"planned":              "needs-confirmation",
+		"apply_queued":         "applying",
+		"post_apply_running":   "applying",
+		"post_apply_completed": "applying",
+		"applied":              "",
+		"discarded":            "",
+	}
+	for status, want := range tests {
+		if got := runStatusGroup(status); got != want {
+			t.Errorf("runStatusGroup(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestRunAge(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	tests := map[string]string{
+		"2024-05-10T11:59:15Z": "45s",
+		"2024-05-10T11:48:00Z": "12m",
+		"2024-05-10T09:00:00Z": "3h",
+		"2024-05-05T12:00:00Z": "5d",
+		"":                     "-",
+	}
+	for created, want := range tests {
+		if got := runAge(created, now); got != want {
+			t.Errorf("runAge(%q) = %q, want %q", created, got, want)
+		}
+	}
+}
+
+func TestRunList_OrgWide(t *testing.T) {
+	var query string
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path != "/api/v2/organizations/test-org/runs" {
+			w.WriteHeader(404)
+			return
+		}
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"data":[{"id":"run-1","type":"runs","attributes":{"status":"planned"},
+			"relationships":{"workspace":{"data":{"id":"ws-1","type":"workspaces"}},"created-by":{"data":{"id":"user-1","type":"users"}}}}]}`))
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+	resetRunListFlags(t)
+
+	rootCmd.SetArgs([]string{"run", "list", "--org", "test-org", "--status-group", "needs-confirmation", "--json=false"})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query == "" {
+		t.Fatal("organization runs were not requested")
+	}
+	q, _ := http.NewRequest("GET", "/?"+query, nil)
+	if got := q.URL.Query().Get("filter[status]"); got != "planned,cost_estimated,policy_checked,policy_override,policy_soft_failed,post_plan_awaiting_decision" {
+		t.Errorf("unexpected status filter %q", got)
+	}
+	if got := q.URL.Query().Get("include"); got != "workspace,created_by" {
+		t.Errorf("unexpected include %q", got)
+	}
+}
+
+func TestQueue_SortsByGroupThenAge(t *testing.T) {
+	pages := 0
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		if r.URL.Path != "/api/v2/organizations/test-org/runs" {
+			w.WriteHeader(404)
+			return
+		}
+		pages++
+		if r.URL.Query().Get("page[number]") == "1" {
+			w.Write([]byte(`{"data":[
+				{"id":"run-apply","type":"runs","attributes":{"status":"applying","created-at":"2024-05-01T00:00:00Z"},
+				 "relationships":{"workspace":{"data":{"id":"ws-1","type":"workspaces"}}}},
+				{"id":"run-new","type":"runs","attributes":{"status":"planned","created-at":"2024-05-03T00:00:00Z"},
+				 "relationships":{"workspace":{"data":{"id":"ws-2","type":"workspaces"}},"created-by":{"data":{"id":"user-1","type":"users"}}}}],
+				"included":[{"id":"ws-1","type":"workspaces","attributes":{"name":"network"}},
+				 {"id":"ws-2","type":"workspaces","attributes":{"name":"app"}},
+				 {"id":"user-1","type":"users","attributes":{"username":"alice"}}],
+				"meta":{"pagination":{"current-page":1,"next-page":2,"total-pages":2}}}`))
+			return
+		}
+		w.Write([]byte(`{"data":[
+			{"id":"run-old","type":"runs","attributes":{"status":"policy_checked","created-at":"2024-05-02T00:00:00Z"},
+			 "relationships":{"workspace":{"data":{"id":"ws-3","type":"workspaces"}}}},
+			{"id":"run-done","type":"runs","attributes":{"status":"applied","created-at":"2024-04-01T00:00:00Z"},
+			 "relationships":{"workspace":{"data":{"id":"ws-3","type":"workspaces"}}}}],
+			"meta":{"pagination":{"current-page":2,"total-pages":2}}}`))
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	out := filepath.Join(t.TempDir(), "queue.json")
+	defer func() { flagJSON = false; flagOutputFile = "" }()
+	rootCmd.SetArgs([]string{"queue", "--org", "test-org", "--json", "-o", out})
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pages != 2 {
+		t.Errorf("expected 2 pages, got %d", pages)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var runs []orgRun
+	if err := json.Unmarshal(data, &runs); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	var ids []string
+	for _, r := range runs {
+		ids = append(ids, r.ID)
+	}
+	if len(ids) != 3 || ids[0] != "run-old" || ids[1] != "run-new" || ids[2] != "run-apply" {
+		t.Fatalf("unexpected order: %v", ids)
+	}
+	if runs[1].Workspace != "app" || runs[1].TriggeredBy != "alice" || runs[1].StatusGroup != "needs-confirmation" {
+		t.Errorf("unexpected run: %+v", runs[1])
+	}
+	if runs[0].Workspace != "ws-3" {
+		t.Errorf("expected workspace ID fallback, got %q", runs[0].Workspace)
+	}
+}
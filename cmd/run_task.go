@@ -1,6 +1,14 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
 
 var runTaskCmd = &cobra.Command{
 	Use:     "run-task",
@@ -11,66 +19,648 @@ var runTaskCmd = &cobra.Command{
 var runTaskListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List run tasks in an organization",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("run-task list")
-	},
+	Long: `List run tasks in an organization.
+
+With --workspace, list the run tasks attached to that workspace along with
+their enforcement level and stages.`,
+	RunE: runRunTaskList,
 }
 
 var runTaskShowCmd = &cobra.Command{
-	Use:   "show [id]",
+	Use:   "show [task]",
 	Short: "Show run task details",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("run-task show")
-	},
+	RunE:  runRunTaskShow,
 }
 
 var runTaskCreateCmd = &cobra.Command{
 	Use:   "create [name]",
 	Short: "Create a new run task",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("run-task create")
-	},
+	RunE:  runRunTaskCreate,
 }
 
 var runTaskUpdateCmd = &cobra.Command{
-	Use:   "update [id]",
+	Use:   "update [task]",
 	Short: "Update a run task",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("run-task update")
-	},
+	RunE:  runRunTaskUpdate,
 }
 
 var runTaskDeleteCmd = &cobra.Command{
-	Use:   "delete [id]",
+	Use:   "delete [task]",
 	Short: "Delete a run task",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("run-task delete")
-	},
+	RunE:  runRunTaskDelete,
+}
+
+var runTaskAttachCmd = &cobra.Command{
+	Use:   "attach [task]",
+	Short: "Attach a run task to a workspace",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRunTaskAttach,
+}
+
+var runTaskDetachCmd = &cobra.Command{
+	Use:   "detach [task]",
+	Short: "Detach a run task from a workspace",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRunTaskDetach,
+}
+
+var runTaskResultsCmd = &cobra.Command{
+	Use:   "task-results [run-id]",
+	Short: "Show run task stages and results for a run",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRunTaskResults,
 }
 
+// runTaskEnforcementLevels are the accepted values for --enforcement.
+var runTaskEnforcementLevels = []string{"advisory", "mandatory"}
+
+// runTaskStages are the accepted values for --stage.
+var runTaskStages = []string{"pre-plan", "post-plan", "pre-apply", "post-apply"}
+
 func init() {
+	runTaskListCmd.Flags().String("workspace", "", "List tasks attached to this workspace (name or ID)")
+
 	// Create flags
 	runTaskCreateCmd.Flags().String("url", "", "Callback URL (required)")
 	runTaskCreateCmd.Flags().String("description", "", "Description")
 	runTaskCreateCmd.Flags().String("hmac-key", "", "HMAC key for verification")
+	runTaskCreateCmd.Flags().String("category", "task", "Run task category")
 	runTaskCreateCmd.Flags().Bool("enabled", true, "Enable the run task")
 
 	// Update flags
 	runTaskUpdateCmd.Flags().String("name", "", "New name")
 	runTaskUpdateCmd.Flags().String("url", "", "Callback URL")
 	runTaskUpdateCmd.Flags().String("description", "", "Description")
+	runTaskUpdateCmd.Flags().String("hmac-key", "", "HMAC key for verification (empty to remove)")
+	runTaskUpdateCmd.Flags().String("category", "", "Run task category")
 	runTaskUpdateCmd.Flags().Bool("enabled", true, "Enable the run task")
 
+	// Attach flags
+	runTaskAttachCmd.Flags().String("workspace", "", "Workspace name or ID (required)")
+	runTaskAttachCmd.Flags().String("enforcement", "advisory", "Enforcement level: advisory or mandatory")
+	runTaskAttachCmd.Flags().StringSlice("stage", []string{"post-plan"}, "Stages to run in: pre-plan, post-plan, pre-apply, post-apply")
+
+	runTaskDetachCmd.Flags().String("workspace", "", "Workspace name or ID (required)")
+
 	runTaskCmd.AddCommand(
 		runTaskListCmd,
 		runTaskShowCmd,
 		runTaskCreateCmd,
 		runTaskUpdateCmd,
 		runTaskDeleteCmd,
+		runTaskAttachCmd,
+		runTaskDetachCmd,
 	)
 	rootCmd.AddCommand(runTaskCmd)
+	runCmd.AddCommand(runTaskResultsCmd)
+}
+
+type runTaskAttrs struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Enabled     bool   `json:"enabled"`
+}
+
+type workspaceTaskAttrs struct {
+	EnforcementLevel string   `json:"enforcement-level"`
+	Stage            string   `json:"stage"`
+	Stages           []string `json:"stages"`
+}
+
+// stageList returns the stages of a workspace task, falling back to the
+// single deprecated stage attribute.
+func (a workspaceTaskAttrs) stageList() []string {
+	if len(a.Stages) > 0 {
+		return a.Stages
+	}
+	if a.Stage != "" {
+		return []string{a.Stage}
+	}
+	return nil
+}
+
+type taskStageAttrs struct {
+	Stage     string `json:"stage"`
+	Status    string `json:"status"`
+	CreatedAt string `json:"created-at"`
+}
+
+type taskResultAttrs struct {
+	Message          string `json:"message"`
+	Status           string `json:"status"`
+	URL              string `json:"url"`
+	TaskName         string `json:"task-name"`
+	Stage            string `json:"stage"`
+	EnforcementLevel string `json:"workspace-task-enforcement-level"`
+}
+
+// resolveRunTaskID resolves a run task name or ID to a run task ID.
+// If the value starts with "task-", it is returned as-is.
+func resolveRunTaskID(client *api.Client, task string) (string, error) {
+	if strings.HasPrefix(task, "task-") {
+		return task, nil
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	if err := client.GetAllPages(fmt.Sprintf("/organizations/%s/tasks?page[size]=100", org), func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a runTaskAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			if a.Name == task && id == "" {
+				id = r.ID
+			}
+		}
+	}); err != nil {
+		return "", fmt.Errorf("resolve run task %q: %w", task, err)
+	}
+	if id == "" {
+		return "", fmt.Errorf("resolve run task %q: not found in organization %s", task, org)
+	}
+	return id, nil
+}
+
+func validateRunTaskStages(stages []string) error {
+	if len(stages) == 0 {
+		return output.NewUsageError("at least one --stage is required")
+	}
+	for _, s := range stages {
+		if !containsStr(runTaskStages, s) {
+			return output.NewUsageError(fmt.Sprintf("unknown stage %q: must be one of %s", s, strings.Join(runTaskStages, ", ")))
+		}
+	}
+	return nil
+}
+
+func runRunTaskList(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	if workspace != "" {
+		return runWorkspaceTaskList(cmd, workspace)
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	opts := GetOutputOptions()
+
+	type taskJSON struct {
+		ID    string       `json:"id"`
+		Attrs runTaskAttrs `json:"attributes"`
+	}
+	var jsonData []taskJSON
+	td := output.TableData{
+		Headers: []string{"ID", "NAME", "ENABLED", "URL", "DESCRIPTION"},
+	}
+
+	if err := client.GetAllPages(fmt.Sprintf("/organizations/%s/tasks?page[size]=100", org), func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a runTaskAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			td.Rows = append(td.Rows, []string{
+				r.ID, a.Name, boolStr(a.Enabled), truncateStr(a.URL, 50), truncateStr(defaultStr(a.Description, "-"), 40),
+			})
+			jsonData = append(jsonData, taskJSON{ID: r.ID, Attrs: a})
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
+
+// runWorkspaceTaskList lists the run tasks attached to a workspace.
+func runWorkspaceTaskList(cmd *cobra.Command, workspace string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Get("/workspaces/"+wsID+"/tasks?include=task", &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	resources, err := jsonapi.ParseList(&doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	taskNames := map[string]string{}
+	for _, inc := range doc.Included {
+		var a runTaskAttrs
+		jsonapi.UnmarshalAttributes(&inc, &a)
+		taskNames[inc.ID] = a.Name
+	}
+
+	opts := GetOutputOptions()
+
+	type wsTaskJSON struct {
+		ID       string             `json:"id"`
+		TaskID   string             `json:"task_id"`
+		TaskName string             `json:"task_name"`
+		Attrs    workspaceTaskAttrs `json:"attributes"`
+	}
+	var jsonData []wsTaskJSON
+	td := output.TableData{
+		Headers: []string{"ID", "TASK", "ENFORCEMENT", "STAGES"},
+	}
+
+	for _, r := range resources {
+		var a workspaceTaskAttrs
+		jsonapi.UnmarshalAttributes(&r, &a)
+		taskID := extractRelationshipID(&r, "task")
+		name := defaultStr(taskNames[taskID], taskID)
+		td.Rows = append(td.Rows, []string{
+			r.ID, name, a.EnforcementLevel, joinTags(a.stageList()),
+		})
+		jsonData = append(jsonData, wsTaskJSON{ID: r.ID, TaskID: taskID, TaskName: name, Attrs: a})
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
+
+func runRunTaskShow(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	taskID, err := resolveRunTaskID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Get("/tasks/"+taskID, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderRunTask(cmd, &doc, "")
+}
+
+func runRunTaskCreate(cmd *cobra.Command, args []string) error {
+	url, _ := cmd.Flags().GetString("url")
+	if url == "" {
+		return output.NewUsageError("--url is required")
+	}
+	description, _ := cmd.Flags().GetString("description")
+	hmacKey, _ := cmd.Flags().GetString("hmac-key")
+	category, _ := cmd.Flags().GetString("category")
+	enabled, _ := cmd.Flags().GetBool("enabled")
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	attrs := map[string]interface{}{
+		"name":     args[0],
+		"url":      url,
+		"category": category,
+		"enabled":  enabled,
+	}
+	if description != "" {
+		attrs["description"] = description
+	}
+	if hmacKey != "" {
+		attrs["hmac-key"] = hmacKey
+	}
+
+	body, err := jsonapi.WrapForCreate("tasks", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post("/organizations/"+org+"/tasks", body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderRunTask(cmd, &doc, "created")
+}
+
+func runRunTaskUpdate(cmd *cobra.Command, args []string) error {
+	attrs := map[string]interface{}{}
+	for _, name := range []string{"name", "url", "description", "hmac-key", "category"} {
+		if cmd.Flags().Changed(name) {
+			v, _ := cmd.Flags().GetString(name)
+			attrs[name] = v
+		}
+	}
+	if cmd.Flags().Changed("enabled") {
+		v, _ := cmd.Flags().GetBool("enabled")
+		attrs["enabled"] = v
+	}
+	if len(attrs) == 0 {
+		return output.NewUsageError("nothing to update: pass at least one flag")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	taskID, err := resolveRunTaskID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	body, err := jsonapi.WrapForUpdate(taskID, "tasks", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Patch("/tasks/"+taskID, body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderRunTask(cmd, &doc, "updated")
+}
+
+func runRunTaskDelete(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	taskID, err := resolveRunTaskID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if err := client.Delete("/tasks/" + taskID); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Run task %s deleted\n", taskID)
+	return nil
+}
+
+func runRunTaskAttach(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	enforcement, _ := cmd.Flags().GetString("enforcement")
+	stages, _ := cmd.Flags().GetStringSlice("stage")
+
+	if workspace == "" {
+		return output.NewUsageError("--workspace is required")
+	}
+	if !containsStr(runTaskEnforcementLevels, enforcement) {
+		return output.NewUsageError(fmt.Sprintf("--enforcement must be one of %s", strings.Join(runTaskEnforcementLevels, ", ")))
+	}
+	if err := validateRunTaskStages(stages); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	taskID, err := resolveRunTaskID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	body := map[string]interface{}{
+		"data": map[string]interface{}{
+			"type": "workspace-tasks",
+			"attributes": map[string]interface{}{
+				"enforcement-level": enforcement,
+				"stages":            stages,
+			},
+			"relationships": map[string]interface{}{
+				"task": map[string]interface{}{
+					"data": map[string]string{"type": "tasks", "id": taskID},
+				},
+			},
+		},
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post("/workspaces/"+wsID+"/tasks", body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a workspaceTaskAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	opts := GetOutputOptions()
+
+	type wsTaskDetail struct {
+		ID          string             `json:"id"`
+		TaskID      string             `json:"task_id"`
+		WorkspaceID string             `json:"workspace_id"`
+		Attrs       workspaceTaskAttrs `json:"attributes"`
+	}
+	data := wsTaskDetail{ID: res.ID, TaskID: taskID, WorkspaceID: wsID, Attrs: a}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Task", taskID},
+			{"Workspace", wsID},
+			{"Enforcement", a.EnforcementLevel},
+			{"Stages", joinTags(a.stageList())},
+		},
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Run task %s attached to workspace %s\n", taskID, wsID)
+	return output.RenderTable(td, data, opts)
+}
+
+func runRunTaskDetach(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	if workspace == "" {
+		return output.NewUsageError("--workspace is required")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	taskID, err := resolveRunTaskID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var wsTaskID string
+	if err := client.GetAllPages("/workspaces/"+wsID+"/tasks?page[size]=100", func(page []jsonapi.Resource) {
+		for _, r := range page {
+			if extractRelationshipID(&r, "task") == taskID {
+				wsTaskID = r.ID
+			}
+		}
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	if wsTaskID == "" {
+		return output.NewNotFoundError(fmt.Sprintf("run task %s is not attached to workspace %s", taskID, wsID))
+	}
+
+	if err := client.Delete("/workspaces/" + wsID + "/tasks/" + wsTaskID); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Run task %s detached from workspace %s\n", taskID, wsID)
+	return nil
+}
+
+// taskResultRow is one task result, or a task stage without results.
+type taskResultRow struct {
+	StageID     string `json:"stage_id"`
+	Stage       string `json:"stage"`
+	StageStatus string `json:"stage_status"`
+	ResultID    string `json:"result_id,omitempty"`
+	Task        string `json:"task,omitempty"`
+	Status      string `json:"status,omitempty"`
+	Enforcement string `json:"enforcement,omitempty"`
+	Message     string `json:"message,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// taskResultRows flattens task stages and their included task results.
+func taskResultRows(doc *jsonapi.Document) ([]taskResultRow, error) {
+	stages, err := jsonapi.ParseList(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	results := map[string]jsonapi.Resource{}
+	for _, inc := range doc.Included {
+		if inc.Type == "task-results" {
+			results[inc.ID] = inc
+		}
+	}
+
+	var rows []taskResultRow
+	for _, s := range stages {
+		var sa taskStageAttrs
+		jsonapi.UnmarshalAttributes(&s, &sa)
+		resultIDs := extractRelationshipIDs(&s, "task-results")
+		if len(resultIDs) == 0 {
+			rows = append(rows, taskResultRow{StageID: s.ID, Stage: sa.Stage, StageStatus: sa.Status})
+			continue
+		}
+		for _, id := range resultIDs {
+			row := taskResultRow{StageID: s.ID, Stage: sa.Stage, StageStatus: sa.Status, ResultID: id}
+			if r, ok := results[id]; ok {
+				var ra taskResultAttrs
+				jsonapi.UnmarshalAttributes(&r, &ra)
+				row.Task = ra.TaskName
+				row.Status = ra.Status
+				row.Enforcement = ra.EnforcementLevel
+				row.Message = ra.Message
+				row.URL = ra.URL
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func runRunTaskResults(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	runID := args[0]
+	var doc jsonapi.Document
+	if err := client.Get("/runs/"+runID+"/task-stages?include=task_results", &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	rows, err := taskResultRows(&doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	opts := GetOutputOptions()
+
+	td := output.TableData{
+		Headers: []string{"STAGE", "STAGE STATUS", "TASK", "STATUS", "ENFORCEMENT", "MESSAGE"},
+	}
+	for _, r := range rows {
+		td.Rows = append(td.Rows, []string{
+			r.Stage, r.StageStatus, defaultStr(r.Task, "-"), defaultStr(r.Status, "-"),
+			defaultStr(r.Enforcement, "-"), truncateStr(defaultStr(r.Message, "-"), 60),
+		})
+	}
+
+	return output.RenderTable(td, rows, opts)
+}
+
+func renderRunTask(cmd *cobra.Command, doc *jsonapi.Document, verb string) error {
+	res, err := jsonapi.ParseSingle(doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a runTaskAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	opts := GetOutputOptions()
+
+	type taskDetail struct {
+		ID    string       `json:"id"`
+		Attrs runTaskAttrs `json:"attributes"`
+	}
+	data := taskDetail{ID: res.ID, Attrs: a}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Name", a.Name},
+			{"URL", a.URL},
+			{"Description", defaultStr(a.Description, "-")},
+			{"Category", a.Category},
+			{"Enabled", boolStr(a.Enabled)},
+		},
+	}
+
+	if verb != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Run task %s %s\n", res.ID, verb)
+	}
+	return output.RenderTable(td, data, opts)
 }
@@ -0,0 +1,451 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// runTaskSignatureHeader carries the hex HMAC-SHA512 of the request body.
+const runTaskSignatureHeader = "X-TFC-Task-Signature"
+
+var runTaskServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local run task endpoint for developing integrations",
+	Long: `Run a local run task endpoint for developing integrations.
+
+Starts an HTTP server that speaks the run task protocol: it verifies the
+X-TFC-Task-Signature HMAC-SHA512 against --hmac-key, acknowledges the request,
+prints one row per request, and then calls back the task result endpoint with
+a result.
+
+The result is chosen, in order of preference, by:
+
+  --script   a command run through "sh -c" with the request payload on stdin.
+             If it prints a JSON result ({"status", "message", "url",
+             "outcomes"}) that is sent; otherwise the exit code decides
+             passed/failed and stdout becomes the message.
+  --rules    a JSON file of rules matched against the request:
+             {"rules": [{"workspace": "prod-*", "stage": "post-plan",
+               "status": "failed", "message": "...", "outcomes": [...]}],
+              "default": {"status": "passed"}}
+             workspace, stage and organization are glob patterns; the first
+             matching rule wins.
+  --status/--message  a fixed result.
+
+Use --record-dir to save each payload as <task_result_id>.json for replaying
+later, and --no-callback to only record requests.`,
+	RunE: runRunTaskServe,
+}
+
+func init() {
+	runTaskServeCmd.Flags().Int("port", 8080, "Port to listen on")
+	runTaskServeCmd.Flags().String("bind", "127.0.0.1", "Address to bind to")
+	runTaskServeCmd.Flags().String("hmac-key", "", "HMAC key configured on the run task (unsigned requests are accepted if empty)")
+	runTaskServeCmd.Flags().String("script", "", "Command that decides the result")
+	runTaskServeCmd.Flags().String("rules", "", "JSON rules file that decides the result")
+	runTaskServeCmd.Flags().String("status", "passed", "Fixed result status: passed or failed")
+	runTaskServeCmd.Flags().String("message", "", "Fixed result message")
+	runTaskServeCmd.Flags().String("record-dir", "", "Directory to save request payloads in")
+	runTaskServeCmd.Flags().Duration("delay", 0, "Wait this long before calling back")
+	runTaskServeCmd.Flags().Bool("no-callback", false, "Do not call back the task result endpoint")
+
+	runTaskCmd.AddCommand(runTaskServeCmd)
+}
+
+// runTaskPayload is the subset of a run task request used for matching and
+// calling back.
+type runTaskPayload struct {
+	PayloadVersion        int    `json:"payload_version"`
+	Stage                 string `json:"stage"`
+	AccessToken           string `json:"access_token"`
+	IsSpeculative         bool   `json:"is_speculative"`
+	OrganizationName      string `json:"organization_name"`
+	RunID                 string `json:"run_id"`
+	RunMessage            string `json:"run_message"`
+	TaskResultID          string `json:"task_result_id"`
+	TaskResultCallbackURL string `json:"task_result_callback_url"`
+	EnforcementLevel      string `json:"task_result_enforcement_level"`
+	WorkspaceID           string `json:"workspace_id"`
+	WorkspaceName         string `json:"workspace_name"`
+}
+
+// taskOutcome is a detailed finding attached to a task result.
+type taskOutcome struct {
+	OutcomeID   string          `json:"outcome-id"`
+	Description string          `json:"description"`
+	Body        string          `json:"body,omitempty"`
+	URL         string          `json:"url,omitempty"`
+	Tags        json.RawMessage `json:"tags,omitempty"`
+}
+
+// taskResult is the result sent back to the task result callback.
+type taskResult struct {
+	Status   string        `json:"status"`
+	Message  string        `json:"message,omitempty"`
+	URL      string        `json:"url,omitempty"`
+	Outcomes []taskOutcome `json:"outcomes,omitempty"`
+}
+
+// taskRule matches requests by glob patterns and yields a result.
+type taskRule struct {
+	Workspace    string `json:"workspace"`
+	Stage        string `json:"stage"`
+	Organization string `json:"organization"`
+	taskResult
+}
+
+type taskRules struct {
+	Rules   []taskRule  `json:"rules"`
+	Default *taskResult `json:"default"`
+}
+
+// globMatch reports whether value matches pattern; an empty pattern matches
+// everything.
+func globMatch(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, err := path.Match(pattern, value)
+	return err == nil && ok
+}
+
+// match returns the result of the first rule matching p, then the default.
+func (r taskRules) match(p runTaskPayload) (taskResult, bool) {
+	for _, rule := range r.Rules {
+		if globMatch(rule.Workspace, p.WorkspaceName) && globMatch(rule.Stage, p.Stage) && globMatch(rule.Organization, p.OrganizationName) {
+			return rule.taskResult, true
+		}
+	}
+	if r.Default != nil {
+		return *r.Default, true
+	}
+	return taskResult{}, false
+}
+
+func loadTaskRules(file string) (taskRules, error) {
+	var rules taskRules
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return rules, err
+	}
+	if err := json.Unmarshal(raw, &rules); err != nil {
+		return rules, fmt.Errorf("parse %s: %w", file, err)
+	}
+	for i, rule := range rules.Rules {
+		if err := validateTaskResultStatus(rule.Status); err != nil {
+			return rules, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	if rules.Default != nil {
+		if err := validateTaskResultStatus(rules.Default.Status); err != nil {
+			return rules, fmt.Errorf("default: %w", err)
+		}
+	}
+	return rules, nil
+}
+
+func validateTaskResultStatus(status string) error {
+	switch status {
+	case "passed", "failed", "running":
+		return nil
+	}
+	return fmt.Errorf("status must be passed, failed or running, got %q", status)
+}
+
+// runTaskScript runs command with the raw payload on stdin and turns its
+// output into a result.
+func runTaskScript(command string, raw []byte, p runTaskPayload) taskResult {
+	c := exec.Command("sh", "-c", command)
+	c.Stdin = bytes.NewReader(raw)
+	var stdout bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = os.Stderr
+	c.Env = append(os.Environ(),
+		"TFC_RUN_ID="+p.RunID,
+		"TFC_WORKSPACE_NAME="+p.WorkspaceName,
+		"TFC_TASK_STAGE="+p.Stage,
+	)
+	runErr := c.Run()
+	return scriptTaskResult(stdout.Bytes(), runErr)
+}
+
+// scriptTaskResult interprets a script's stdout and exit status.
+func scriptTaskResult(stdout []byte, runErr error) taskResult {
+	var res taskResult
+	if json.Unmarshal(bytes.TrimSpace(stdout), &res) == nil && validateTaskResultStatus(res.Status) == nil {
+		return res
+	}
+	res = taskResult{Status: "passed", Message: strings.TrimSpace(string(stdout))}
+	if runErr != nil {
+		res.Status = "failed"
+		if res.Message == "" {
+			res.Message = runErr.Error()
+		}
+	}
+	return res
+}
+
+// sendTaskResult PATCHes a result to the task result callback URL.
+func sendTaskResult(ctx context.Context, callbackURL, accessToken string, res taskResult) error {
+	attrs := map[string]interface{}{"status": res.Status}
+	if res.Message != "" {
+		attrs["message"] = res.Message
+	}
+	if res.URL != "" {
+		attrs["url"] = res.URL
+	}
+	data := map[string]interface{}{
+		"type":       "task-results",
+		"attributes": attrs,
+	}
+	if len(res.Outcomes) > 0 {
+		outcomes := make([]map[string]interface{}, 0, len(res.Outcomes))
+		for _, o := range res.Outcomes {
+			outcomes = append(outcomes, map[string]interface{}{
+				"type":       "task-result-outcomes",
+				"attributes": o,
+			})
+		}
+		data["relationships"] = map[string]interface{}{
+			"outcomes": map[string]interface{}{"data": outcomes},
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/vnd.api+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 500))
+		return fmt.Errorf("callback returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// newRunTaskHandler returns a handler that verifies and acknowledges run task
+// requests, passing each one to onRequest.
+func newRunTaskHandler(hmacKey string, onRequest func([]byte, runTaskPayload), logf func(string, ...interface{})) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "read body", http.StatusBadRequest)
+			return
+		}
+
+		if hmacKey != "" && !verifyHMACSHA512(body, hmacKey, r.Header.Get(runTaskSignatureHeader)) {
+			logf("Rejected request from %s: invalid or missing %s", r.RemoteAddr, runTaskSignatureHeader)
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		var payload runTaskPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			logf("Rejected request from %s: %v", r.RemoteAddr, err)
+			http.Error(w, "invalid payload", http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		onRequest(body, payload)
+	})
+}
+
+// runTaskEvent is one handled run task request, for output.
+type runTaskEvent struct {
+	ReceivedAt    string     `json:"received_at"`
+	Stage         string     `json:"stage"`
+	WorkspaceName string     `json:"workspace_name"`
+	RunID         string     `json:"run_id"`
+	TaskResultID  string     `json:"task_result_id"`
+	Result        taskResult `json:"result"`
+	Callback      string     `json:"callback"`
+}
+
+// recordTaskPayload writes a raw payload to dir, named after its task result
+// ID. The ID comes from the unauthenticated request, so anything that is not
+// a plain taskrs- ID is replaced by a timestamp.
+func recordTaskPayload(dir string, raw []byte, p runTaskPayload) error {
+	name := p.TaskResultID
+	if !strings.HasPrefix(name, "taskrs-") || filepath.Base(name) != name {
+		name = strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+	return os.WriteFile(filepath.Join(dir, name+".json"), raw, 0o644)
+}
+
+func runRunTaskServe(cmd *cobra.Command, args []string) error {
+	port, _ := cmd.Flags().GetInt("port")
+	bind, _ := cmd.Flags().GetString("bind")
+	hmacKey, _ := cmd.Flags().GetString("hmac-key")
+	script, _ := cmd.Flags().GetString("script")
+	rulesFile, _ := cmd.Flags().GetString("rules")
+	status, _ := cmd.Flags().GetString("status")
+	message, _ := cmd.Flags().GetString("message")
+	recordDir, _ := cmd.Flags().GetString("record-dir")
+	delay, _ := cmd.Flags().GetDuration("delay")
+	noCallback, _ := cmd.Flags().GetBool("no-callback")
+
+	if flagOutputFile != "" {
+		return output.NewUsageError("--output is not supported by serve; use --record-dir or redirect stdout")
+	}
+	if script != "" && rulesFile != "" {
+		return output.NewUsageError("--script and --rules are mutually exclusive")
+	}
+	if err := validateTaskResultStatus(status); err != nil {
+		return output.NewUsageError("--" + err.Error())
+	}
+
+	var rules taskRules
+	if rulesFile != "" {
+		var err error
+		if rules, err = loadTaskRules(rulesFile); err != nil {
+			return output.NewUsageError(fmt.Sprintf("invalid --rules: %v", err))
+		}
+	}
+	if recordDir != "" {
+		if err := os.MkdirAll(recordDir, 0o755); err != nil {
+			return output.NewInternalError(fmt.Sprintf("create record dir: %v", err))
+		}
+	}
+
+	stderr := cmd.ErrOrStderr()
+	logf := func(format string, args ...interface{}) {
+		fmt.Fprintf(stderr, format+"\n", args...)
+	}
+	if hmacKey == "" {
+		logf("Warning: no --hmac-key set, signatures will not be verified")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := GetOutputOptions()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	first := true
+
+	decide := func(raw []byte, p runTaskPayload) taskResult {
+		switch {
+		case script != "":
+			return runTaskScript(script, raw, p)
+		case rulesFile != "":
+			if res, ok := rules.match(p); ok {
+				return res
+			}
+		}
+		return taskResult{Status: status, Message: message}
+	}
+
+	onRequest := func(raw []byte, p runTaskPayload) {
+		if recordDir != "" {
+			if err := recordTaskPayload(recordDir, raw, p); err != nil {
+				logf("record payload: %v", err)
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ev := runTaskEvent{
+				ReceivedAt:    time.Now().UTC().Format(time.RFC3339),
+				Stage:         p.Stage,
+				WorkspaceName: p.WorkspaceName,
+				RunID:         p.RunID,
+				TaskResultID:  p.TaskResultID,
+				Result:        decide(raw, p),
+				Callback:      "skipped",
+			}
+
+			if !noCallback && p.TaskResultCallbackURL != "" {
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+				}
+				if err := sendTaskResult(ctx, p.TaskResultCallbackURL, p.AccessToken, ev.Result); err != nil {
+					logf("callback for %s: %v", defaultStr(p.TaskResultID, "request"), err)
+					ev.Callback = "failed"
+				} else {
+					ev.Callback = "sent"
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			td := output.TableData{
+				Rows: [][]string{{
+					ev.ReceivedAt, defaultStr(ev.Stage, "-"), defaultStr(ev.WorkspaceName, "-"), defaultStr(ev.RunID, "-"),
+					ev.Result.Status, ev.Callback, truncateStr(defaultStr(ev.Result.Message, "-"), 50),
+				}},
+			}
+			if first {
+				td.Headers = []string{"RECEIVED", "STAGE", "WORKSPACE", "RUN", "RESULT", "CALLBACK", "MESSAGE"}
+				first = false
+			}
+			if err := output.RenderTable(td, ev, opts); err != nil {
+				logf("render event: %v", err)
+			}
+		}()
+	}
+
+	addr := net.JoinHostPort(bind, strconv.Itoa(port))
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           newRunTaskHandler(hmacKey, onRequest, logf),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.ListenAndServe()
+	}()
+	logf("Listening for run task requests on http://%s", addr)
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return output.NewInternalError(fmt.Sprintf("listen: %v", err))
+		}
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+		wg.Wait()
+		logf("Stopped")
+	}
+	return nil
+}
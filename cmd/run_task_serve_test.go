@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testRunTaskPayload = `{
+  "payload_version": 1,
+  "stage": "post-plan",
+  "access_token": "task-token",
+  "organization_name": "acme",
+  "run_id": "run-123",
+  "task_result_id": "taskrs-abc",
+  "task_result_callback_url": "http://example.invalid/callback",
+  "workspace_id": "ws-123",
+  "workspace_name": "prod-app"
+}`
+
+func TestRunTaskHandler_Signature(t *testing.T) {
+	var got []runTaskPayload
+	h := newRunTaskHandler("secret", func(raw []byte, p runTaskPayload) {
+		got = append(got, p)
+	}, t.Logf)
+
+	req := httptest.NewRequest("POST", "/", bytes.NewBufferString(testRunTaskPayload))
+	req.Header.Set(runTaskSignatureHeader, signNotification(testRunTaskPayload, "wrong"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized || len(got) != 0 {
+		t.Fatalf("expected bad signature to be rejected, got %d with %d requests", rec.Code, len(got))
+	}
+
+	req = httptest.NewRequest("POST", "/", bytes.NewBufferString(testRunTaskPayload))
+	req.Header.Set(runTaskSignatureHeader, signNotification(testRunTaskPayload, "secret"))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || len(got) != 1 {
+		t.Fatalf("expected valid request to be accepted, got %d with %d requests", rec.Code, len(got))
+	}
+	if got[0].TaskResultID != "taskrs-abc" || got[0].Stage != "post-plan" || got[0].AccessToken != "task-token" {
+		t.Errorf("unexpected payload: %+v", got[0])
+	}
+}
+
+func TestTaskRulesMatch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.json")
+	os.WriteFile(file, []byte(`{
+		"rules": [
+			{"workspace": "prod-*", "stage": "pre-apply", "status": "failed", "message": "frozen"},
+			{"workspace": "prod-*", "status": "failed", "message": "prod needs review",
+			 "outcomes": [{"outcome-id": "R1", "description": "review required"}]}
+		],
+		"default": {"status": "passed", "message": "ok"}
+	}`), 0o644)
+
+	rules, err := loadTaskRules(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, ok := rules.match(runTaskPayload{WorkspaceName: "prod-app", Stage: "post-plan"})
+	if !ok || res.Status != "failed" || res.Message != "prod needs review" || len(res.Outcomes) != 1 {
+		t.Errorf("expected second rule to match, got %+v", res)
+	}
+	res, _ = rules.match(runTaskPayload{WorkspaceName: "dev-app", Stage: "post-plan"})
+	if res.Status != "passed" || res.Message != "ok" {
+		t.Errorf("expected default result, got %+v", res)
+	}
+
+	os.WriteFile(file, []byte(`{"rules": [{"status": "maybe"}]}`), 0o644)
+	if _, err := loadTaskRules(file); err == nil {
+		t.Error("expected invalid status to be rejected")
+	}
+}
+
+func TestScriptTaskResult(t *testing.T) {
+	res := scriptTaskResult([]byte(`{"status": "failed", "message": "3 issues"}`), nil)
+	if res.Status != "failed" || res.Message != "3 issues" {
+		t.Errorf("expected JSON result to be used, got %+v", res)
+	}
+	res = scriptTaskResult([]byte("all good\n"), nil)
+	if res.Status != "passed" || res.Message != "all good" {
+		t.Errorf("expected exit 0 to pass with stdout message, got %+v", res)
+	}
+	res = scriptTaskResult(nil, errors.New("exit status 1"))
+	if res.Status != "failed" || res.Message != "exit status 1" {
+		t.Errorf("expected non-zero exit to fail, got %+v", res)
+	}
+}
+
+func TestSendTaskResult(t *testing.T) {
+	var auth, method string
+	var body struct {
+		Data struct {
+			Type          string            `json:"type"`
+			Attributes    map[string]string `json:"attributes"`
+			Relationships struct {
+				Outcomes struct {
+					Data []struct {
+						Type       string      `json:"type"`
+						Attributes taskOutcome `json:"attributes"`
+					} `json:"data"`
+				} `json:"outcomes"`
+			} `json:"relationships"`
+		} `json:"data"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		method = r.Method
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(200)
+	}))
+	defer srv.Close()
+
+	res := taskResult{
+		Status:   "failed",
+		Message:  "policy violation",
+		Outcomes: []taskOutcome{{OutcomeID: "P-1", Description: "bucket is public"}},
+	}
+	if err := sendTaskResult(context.Background(), srv.URL, "task-token", res); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if method != "PATCH" || auth != "Bearer task-token" {
+		t.Errorf("expected PATCH with bearer token, got %s %q", method, auth)
+	}
+	if body.Data.Type != "task-results" || body.Data.Attributes["status"] != "failed" || body.Data.Attributes["message"] != "policy violation" {
+		t.Errorf("unexpected result body: %+v", body.Data)
+	}
+	outcomes := body.Data.Relationships.Outcomes.Data
+	if len(outcomes) != 1 || outcomes[0].Type != "task-result-outcomes" || outcomes[0].Attributes.OutcomeID != "P-1" {
+		t.Errorf("unexpected outcomes: %+v", outcomes)
+	}
+}
+
+func TestRecordTaskPayload_RejectsTraversal(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "records")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"../escaped", "taskrs-../../escaped", "/tmp/escaped"} {
+		if err := recordTaskPayload(dir, []byte("{}"), runTaskPayload{TaskResultID: id}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", id, err)
+		}
+	}
+	if err := recordTaskPayload(dir, []byte("{}"), runTaskPayload{TaskResultID: "taskrs-abc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	outside, _ := filepath.Glob(filepath.Join(root, "*.json"))
+	if len(outside) != 0 {
+		t.Errorf("payload written outside the record dir: %v", outside)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "taskrs-abc.json")); err != nil {
+		t.Errorf("expected taskrs-abc.json: %v", err)
+	}
+	recorded, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(recorded) != 4 {
+		t.Errorf("expected 4 recorded payloads, got %v", recorded)
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+)
+
+func TestTaskResultRows(t *testing.T) {
+	raw := `{
+		"data": [
+			{"id": "ts-pre", "type": "task-stages", "attributes": {"stage": "pre-plan", "status": "passed"},
+			 "relationships": {"task-results": {"data": []}}},
+			{"id": "ts-post", "type": "task-stages", "attributes": {"stage": "post-plan", "status": "failed"},
+			 "relationships": {"task-results": {"data": [{"type": "task-results", "id": "taskrs-1"}, {"type": "task-results", "id": "taskrs-2"}]}}}
+		],
+		"included": [
+			{"id": "taskrs-1", "type": "task-results", "attributes": {"task-name": "scanner", "status": "failed",
+			 "message": "2 high findings", "workspace-task-enforcement-level": "mandatory"}},
+			{"id": "taskrs-2", "type": "task-results", "attributes": {"task-name": "cost", "status": "passed",
+			 "workspace-task-enforcement-level": "advisory"}}
+		]
+	}`
+	var doc jsonapi.Document
+	if err := json.Unmarshal([]byte(raw), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := taskResultRows(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows (one empty stage, two results), got %d", len(rows))
+	}
+	if rows[0].Stage != "pre-plan" || rows[0].Task != "" {
+		t.Errorf("expected stage without results first, got %+v", rows[0])
+	}
+	if rows[1].Task != "scanner" || rows[1].Status != "failed" || rows[1].Enforcement != "mandatory" || rows[1].Message != "2 high findings" {
+		t.Errorf("unexpected result row: %+v", rows[1])
+	}
+	if rows[2].Task != "cost" || rows[2].StageStatus != "failed" {
+		t.Errorf("unexpected result row: %+v", rows[2])
+	}
+}
+
+func TestWorkspaceTaskStageList(t *testing.T) {
+	if got := (workspaceTaskAttrs{Stage: "post-plan"}).stageList(); len(got) != 1 || got[0] != "post-plan" {
+		t.Errorf("expected fallback to single stage, got %v", got)
+	}
+	if got := (workspaceTaskAttrs{Stage: "post-plan", Stages: []string{"pre-plan", "pre-apply"}}).stageList(); len(got) != 2 {
+		t.Errorf("expected stages to take precedence, got %v", got)
+	}
+}
+
+func TestRunTaskAttach(t *testing.T) {
+	var capturedBody map[string]interface{}
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/tasks":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"id": "task-scan", "type": "tasks", "attributes": map[string]interface{}{"name": "scanner"}},
+				},
+			})
+		case r.Method == "POST" && r.URL.Path == "/api/v2/workspaces/ws-abc/tasks":
+			json.NewDecoder(r.Body).Decode(&capturedBody)
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "wstask-1",
+					"type": "workspace-tasks",
+					"attributes": map[string]interface{}{
+						"enforcement-level": "mandatory", "stages": []string{"pre-plan", "post-plan"},
+					},
+				},
+			})
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"run-task", "attach", "--org", "test-org", "scanner", "--workspace", "ws-abc",
+		"--enforcement", "mandatory", "--stage", "pre-plan,post-plan", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := capturedBody["data"].(map[string]interface{})
+	attrs := data["attributes"].(map[string]interface{})
+	if attrs["enforcement-level"] != "mandatory" {
+		t.Errorf("expected mandatory enforcement, got %v", attrs["enforcement-level"])
+	}
+	if stages, _ := attrs["stages"].([]interface{}); len(stages) != 2 || stages[0] != "pre-plan" {
+		t.Errorf("unexpected stages: %v", attrs["stages"])
+	}
+	task := data["relationships"].(map[string]interface{})["task"].(map[string]interface{})["data"].(map[string]interface{})
+	if task["id"] != "task-scan" {
+		t.Errorf("expected resolved task id, got %v", task["id"])
+	}
+}
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
+
 	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
 	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
 	"github.com/spf13/cobra"
@@ -18,7 +22,7 @@ var teamListCmd = &cobra.Command{
 }
 
 var teamShowCmd = &cobra.Command{
-	Use:   "show [id]",
+	Use:   "show [team]",
 	Short: "Show team details",
 	Args:  cobra.ExactArgs(1),
 	RunE:  runTeamShow,
@@ -28,38 +32,54 @@ var teamCreateCmd = &cobra.Command{
 	Use:   "create [name]",
 	Short: "Create a new team",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("team create")
-	},
+	RunE:  runTeamCreate,
 }
 
 var teamUpdateCmd = &cobra.Command{
-	Use:   "update [id]",
+	Use:   "update [team]",
 	Short: "Update a team",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("team update")
-	},
+	Long: `Update a team by name or ID.
+
+Only flags that are passed are changed, so "--manage-workspaces=false" revokes
+a single organization permission and leaves the others alone.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTeamUpdate,
 }
 
 var teamDeleteCmd = &cobra.Command{
-	Use:   "delete [id]",
+	Use:   "delete [team]",
 	Short: "Delete a team",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("team delete")
-	},
+	RunE:  runTeamDelete,
+}
+
+// teamVisibilities are the accepted values for --visibility.
+var teamVisibilities = []string{"secret", "organization"}
+
+// teamOrgAccessFlags are the organization-access permissions exposed as flags.
+var teamOrgAccessFlags = []string{
+	"manage-workspaces",
+	"manage-projects",
+	"manage-modules",
+	"manage-providers",
+	"manage-policies",
 }
 
 func init() {
 	teamCreateCmd.Flags().String("visibility", "secret", "Visibility: secret or organization")
 	teamCreateCmd.Flags().Bool("manage-workspaces", false, "Can manage workspaces")
+	teamCreateCmd.Flags().Bool("manage-projects", false, "Can manage projects")
 	teamCreateCmd.Flags().Bool("manage-modules", false, "Can manage modules")
 	teamCreateCmd.Flags().Bool("manage-providers", false, "Can manage providers")
 	teamCreateCmd.Flags().Bool("manage-policies", false, "Can manage policies")
 
 	teamUpdateCmd.Flags().String("name", "", "New name")
 	teamUpdateCmd.Flags().String("visibility", "", "Visibility: secret or organization")
+	teamUpdateCmd.Flags().Bool("manage-workspaces", false, "Can manage workspaces")
+	teamUpdateCmd.Flags().Bool("manage-projects", false, "Can manage projects")
+	teamUpdateCmd.Flags().Bool("manage-modules", false, "Can manage modules")
+	teamUpdateCmd.Flags().Bool("manage-providers", false, "Can manage providers")
+	teamUpdateCmd.Flags().Bool("manage-policies", false, "Can manage policies")
 
 	teamCmd.AddCommand(
 		teamListCmd,
@@ -80,6 +100,7 @@ type teamAttrs struct {
 		ManageModules    bool `json:"manage-modules"`
 		ManageProviders  bool `json:"manage-providers"`
 		ManagePolicies   bool `json:"manage-policies"`
+		ManageProjects   bool `json:"manage-projects"`
 	} `json:"organization-access"`
 }
 
@@ -132,13 +153,129 @@ func runTeamShow(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	teamID := args[0]
+	teamID, err := resolveTeamID(args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
 	var doc jsonapi.Document
 	if err := client.Get("/teams/"+teamID, &doc); err != nil {
 		return output.NewAPIError(err.Error())
 	}
 
-	res, err := jsonapi.ParseSingle(&doc)
+	return renderTeam(cmd, &doc, "")
+}
+
+func runTeamCreate(cmd *cobra.Command, args []string) error {
+	visibility, _ := cmd.Flags().GetString("visibility")
+	if !containsStr(teamVisibilities, visibility) {
+		return output.NewUsageError(fmt.Sprintf("--visibility must be one of %s", strings.Join(teamVisibilities, ", ")))
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	orgAccess := map[string]interface{}{}
+	for _, name := range teamOrgAccessFlags {
+		v, _ := cmd.Flags().GetBool(name)
+		orgAccess[name] = v
+	}
+	attrs := map[string]interface{}{
+		"name":                args[0],
+		"visibility":          visibility,
+		"organization-access": orgAccess,
+	}
+
+	body, err := jsonapi.WrapForCreate("teams", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post("/organizations/"+org+"/teams", body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderTeam(cmd, &doc, "created")
+}
+
+func runTeamUpdate(cmd *cobra.Command, args []string) error {
+	attrs := map[string]interface{}{}
+	if cmd.Flags().Changed("name") {
+		v, _ := cmd.Flags().GetString("name")
+		attrs["name"] = v
+	}
+	if cmd.Flags().Changed("visibility") {
+		v, _ := cmd.Flags().GetString("visibility")
+		if !containsStr(teamVisibilities, v) {
+			return output.NewUsageError(fmt.Sprintf("--visibility must be one of %s", strings.Join(teamVisibilities, ", ")))
+		}
+		attrs["visibility"] = v
+	}
+	orgAccess := map[string]interface{}{}
+	for _, name := range teamOrgAccessFlags {
+		if cmd.Flags().Changed(name) {
+			v, _ := cmd.Flags().GetBool(name)
+			orgAccess[name] = v
+		}
+	}
+	if len(orgAccess) > 0 {
+		attrs["organization-access"] = orgAccess
+	}
+	if len(attrs) == 0 {
+		return output.NewUsageError("nothing to update: pass --name, --visibility or a --manage-* flag")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	teamID, err := resolveTeamID(args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	body, err := jsonapi.WrapForUpdate(teamID, "teams", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Patch("/teams/"+teamID, body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderTeam(cmd, &doc, "updated")
+}
+
+func runTeamDelete(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	teamID, err := resolveTeamID(args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if err := client.Delete("/teams/" + teamID); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Team %s deleted\n", teamID)
+	return nil
+}
+
+func renderTeam(cmd *cobra.Command, doc *jsonapi.Document, verb string) error {
+	res, err := jsonapi.ParseSingle(doc)
 	if err != nil {
 		return output.NewAPIError(err.Error())
 	}
@@ -162,11 +299,51 @@ func runTeamShow(cmd *cobra.Command, args []string) error {
 			{"Visibility", a.Visibility},
 			{"Users", itoa(a.UsersCount)},
 			{"Manage Workspaces", boolStr(a.OrganizationAccess.ManageWorkspaces)},
+			{"Manage Projects", boolStr(a.OrganizationAccess.ManageProjects)},
 			{"Manage Modules", boolStr(a.OrganizationAccess.ManageModules)},
 			{"Manage Providers", boolStr(a.OrganizationAccess.ManageProviders)},
 			{"Manage Policies", boolStr(a.OrganizationAccess.ManagePolicies)},
 		},
 	}
 
+	if verb != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Team %s %s\n", res.ID, verb)
+	}
 	return output.RenderTable(td, data, opts)
 }
+
+// resolveTeamID resolves a team name or ID to a team ID.
+// If the value starts with "team-", it is returned as-is. Otherwise, it is
+// looked up by name using the organization from --org / TFC_ORG.
+func resolveTeamID(team string) (string, error) {
+	if strings.HasPrefix(team, "team-") {
+		return team, nil
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return "", err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return "", err
+	}
+
+	var doc jsonapi.Document
+	path := fmt.Sprintf("/organizations/%s/teams?filter[names]=%s", org, url.QueryEscape(team))
+	if err := client.Get(path, &doc); err != nil {
+		return "", fmt.Errorf("resolve team %q: %w", team, err)
+	}
+	resources, err := jsonapi.ParseList(&doc)
+	if err != nil {
+		return "", fmt.Errorf("resolve team %q: %w", team, err)
+	}
+	for _, r := range resources {
+		var a teamAttrs
+		jsonapi.UnmarshalAttributes(&r, &a)
+		if a.Name == team {
+			return r.ID, nil
+		}
+	}
+	return "", fmt.Errorf("resolve team %q: not found in organization %s", team, org)
+}
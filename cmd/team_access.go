@@ -1,6 +1,13 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
 
 var teamAccessCmd = &cobra.Command{
 	Use:     "team-access",
@@ -11,58 +18,82 @@ var teamAccessCmd = &cobra.Command{
 var teamAccessListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List team access for a workspace",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("team-access list")
-	},
+	RunE:  runTeamAccessList,
 }
 
 var teamAccessShowCmd = &cobra.Command{
 	Use:   "show [id]",
-	Short: "Show team access details",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("team-access show")
-	},
+	Short: "Show team access details (by ID, or by --workspace and --team)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runTeamAccessShow,
 }
 
 var teamAccessAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Add team access to a workspace",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("team-access add")
-	},
+	RunE:  runTeamAccessAdd,
 }
 
 var teamAccessUpdateCmd = &cobra.Command{
 	Use:   "update [id]",
-	Short: "Update team access",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("team-access update")
-	},
+	Short: "Update team access (by ID, or by --workspace and --team)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runTeamAccessUpdate,
 }
 
 var teamAccessRemoveCmd = &cobra.Command{
 	Use:   "remove [id]",
-	Short: "Remove team access from a workspace",
-	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("team-access remove")
-	},
+	Short: "Remove team access from a workspace (by ID, or by --workspace and --team)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runTeamAccessRemove,
+}
+
+// teamAccessLevels are the fixed access levels plus "custom".
+var teamAccessLevels = []string{"read", "plan", "write", "admin", "custom"}
+
+// teamAccessPermissions lists the granular permissions used with --access custom
+// and the values each accepts; nil means the permission is a boolean.
+var teamAccessPermissions = []struct {
+	Name   string
+	Values []string
+}{
+	{"runs", []string{"read", "plan", "apply"}},
+	{"variables", []string{"none", "read", "write"}},
+	{"state-versions", []string{"none", "read-outputs", "read", "write"}},
+	{"sentinel-mocks", []string{"none", "read"}},
+	{"workspace-locking", nil},
+	{"run-tasks", nil},
 }
 
 func init() {
 	// List flags
-	teamAccessListCmd.Flags().String("workspace", "", "Workspace ID (required)")
+	teamAccessListCmd.Flags().String("workspace", "", "Workspace name or ID (required)")
+
+	// Lookup flags for show/update/remove without an ID
+	for _, c := range []*cobra.Command{teamAccessShowCmd, teamAccessUpdateCmd, teamAccessRemoveCmd} {
+		c.Flags().String("workspace", "", "Workspace name or ID (with --team, instead of an ID)")
+		c.Flags().String("team", "", "Team name or ID (with --workspace, instead of an ID)")
+	}
 
 	// Add flags
-	teamAccessAddCmd.Flags().String("workspace", "", "Workspace ID (required)")
-	teamAccessAddCmd.Flags().String("team", "", "Team ID (required)")
+	teamAccessAddCmd.Flags().String("workspace", "", "Workspace name or ID (required)")
+	teamAccessAddCmd.Flags().String("team", "", "Team name or ID (required)")
 	teamAccessAddCmd.Flags().String("access", "read", "Access level: read, plan, write, admin, custom")
 
 	// Update flags
 	teamAccessUpdateCmd.Flags().String("access", "", "Access level: read, plan, write, admin, custom")
 
+	// Custom permission flags
+	for _, c := range []*cobra.Command{teamAccessAddCmd, teamAccessUpdateCmd} {
+		for _, p := range teamAccessPermissions {
+			if p.Values == nil {
+				c.Flags().Bool(p.Name, false, fmt.Sprintf("Custom access: allow %s", p.Name))
+			} else {
+				c.Flags().String(p.Name, "", fmt.Sprintf("Custom access: %s permission (%s)", p.Name, joinTags(p.Values)))
+			}
+		}
+	}
+
 	teamAccessCmd.AddCommand(
 		teamAccessListCmd,
 		teamAccessShowCmd,
@@ -72,3 +103,329 @@ func init() {
 	)
 	rootCmd.AddCommand(teamAccessCmd)
 }
+
+type teamAccessAttrs struct {
+	Access           string `json:"access"`
+	Runs             string `json:"runs"`
+	Variables        string `json:"variables"`
+	StateVersions    string `json:"state-versions"`
+	SentinelMocks    string `json:"sentinel-mocks"`
+	WorkspaceLocking bool   `json:"workspace-locking"`
+	RunTasks         bool   `json:"run-tasks"`
+}
+
+// teamAccessAttributes builds the access attributes from flags. Passing any
+// granular permission implies --access custom; mixing one with another
+// access level is an error.
+func teamAccessAttributes(cmd *cobra.Command, requireAccess bool) (map[string]interface{}, error) {
+	attrs := map[string]interface{}{}
+	access, _ := cmd.Flags().GetString("access")
+	if access != "" && (requireAccess || cmd.Flags().Changed("access")) {
+		if !containsStr(teamAccessLevels, access) {
+			return nil, output.NewUsageError(fmt.Sprintf("invalid --access %q: must be one of %s", access, joinTags(teamAccessLevels)))
+		}
+		attrs["access"] = access
+	}
+
+	custom := 0
+	for _, p := range teamAccessPermissions {
+		if !cmd.Flags().Changed(p.Name) {
+			continue
+		}
+		custom++
+		if p.Values == nil {
+			v, _ := cmd.Flags().GetBool(p.Name)
+			attrs[p.Name] = v
+			continue
+		}
+		v, _ := cmd.Flags().GetString(p.Name)
+		if !containsStr(p.Values, v) {
+			return nil, output.NewUsageError(fmt.Sprintf("invalid --%s %q: must be one of %s", p.Name, v, joinTags(p.Values)))
+		}
+		attrs[p.Name] = v
+	}
+
+	if custom > 0 {
+		if a, ok := attrs["access"]; ok && a != "custom" && cmd.Flags().Changed("access") {
+			return nil, output.NewUsageError("granular permission flags require --access custom")
+		}
+		attrs["access"] = "custom"
+	}
+	return attrs, nil
+}
+
+// resolveTeamAccessID returns the team-workspace ID from the argument, or
+// looks it up from --workspace and --team.
+func resolveTeamAccessID(client *api.Client, cmd *cobra.Command, args []string) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+	workspace, _ := cmd.Flags().GetString("workspace")
+	team, _ := cmd.Flags().GetString("team")
+	if workspace == "" || team == "" {
+		return "", output.NewUsageError("pass a team access ID, or both --workspace and --team")
+	}
+
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return "", output.NewAPIError(err.Error())
+	}
+	teamID, err := resolveTeamID(team)
+	if err != nil {
+		return "", output.NewAPIError(err.Error())
+	}
+
+	var found string
+	path := fmt.Sprintf("/team-workspaces?filter[workspace][id]=%s", wsID)
+	if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+		for _, r := range page {
+			if extractRelationshipID(&r, "team") == teamID {
+				found = r.ID
+			}
+		}
+	}); err != nil {
+		return "", output.NewAPIError(err.Error())
+	}
+	if found == "" {
+		return "", output.NewNotFoundError(fmt.Sprintf("team %s has no access to workspace %s", team, workspace))
+	}
+	return found, nil
+}
+
+func runTeamAccessList(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	if workspace == "" {
+		return output.NewUsageError("--workspace is required")
+	}
+
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	// Sideload teams so the list can show names rather than only IDs.
+	var resources []jsonapi.Resource
+	teamNames := map[string]string{}
+	path := fmt.Sprintf("/team-workspaces?filter[workspace][id]=%s&include=team", wsID)
+	if err := client.GetAllDocuments(path, func(doc *jsonapi.Document) error {
+		list, err := jsonapi.ParseList(doc)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, list...)
+		for _, inc := range doc.Included {
+			if inc.Type == "teams" {
+				var t teamAttrs
+				jsonapi.UnmarshalAttributes(&inc, &t)
+				teamNames[inc.ID] = t.Name
+			}
+		}
+		return nil
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	opts := GetOutputOptions()
+
+	type taJSON struct {
+		ID       string          `json:"id"`
+		TeamID   string          `json:"team_id"`
+		TeamName string          `json:"team_name"`
+		Attrs    teamAccessAttrs `json:"attributes"`
+	}
+	var jsonData []taJSON
+	td := output.TableData{
+		Headers: []string{"ID", "TEAM", "ACCESS", "RUNS", "VARIABLES", "STATE", "LOCKING", "RUN TASKS"},
+	}
+
+	for _, r := range resources {
+		var a teamAccessAttrs
+		jsonapi.UnmarshalAttributes(&r, &a)
+		teamID := extractRelationshipID(&r, "team")
+		name := teamNames[teamID]
+		td.Rows = append(td.Rows, []string{
+			r.ID, defaultStr(name, teamID), a.Access, a.Runs, a.Variables, a.StateVersions,
+			boolStr(a.WorkspaceLocking), boolStr(a.RunTasks),
+		})
+		jsonData = append(jsonData, taJSON{ID: r.ID, TeamID: teamID, TeamName: name, Attrs: a})
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
+
+func runTeamAccessShow(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	taID, err := resolveTeamAccessID(client, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	var doc jsonapi.Document
+	if err := client.Get("/team-workspaces/"+taID+"?include=team", &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderTeamAccess(cmd, &doc, "")
+}
+
+func runTeamAccessAdd(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	team, _ := cmd.Flags().GetString("team")
+	if workspace == "" || team == "" {
+		return output.NewUsageError("--workspace and --team are required")
+	}
+
+	attrs, err := teamAccessAttributes(cmd, true)
+	if err != nil {
+		return err
+	}
+
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	teamID, err := resolveTeamID(team)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	body := map[string]interface{}{
+		"data": map[string]interface{}{
+			"type":       "team-workspaces",
+			"attributes": attrs,
+			"relationships": map[string]interface{}{
+				"workspace": map[string]interface{}{
+					"data": map[string]interface{}{"type": "workspaces", "id": wsID},
+				},
+				"team": map[string]interface{}{
+					"data": map[string]interface{}{"type": "teams", "id": teamID},
+				},
+			},
+		},
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post("/team-workspaces", body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderTeamAccess(cmd, &doc, "created")
+}
+
+func runTeamAccessUpdate(cmd *cobra.Command, args []string) error {
+	attrs, err := teamAccessAttributes(cmd, false)
+	if err != nil {
+		return err
+	}
+	if len(attrs) == 0 {
+		return output.NewUsageError("nothing to update: pass --access or a granular permission flag")
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	taID, err := resolveTeamAccessID(client, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	body, err := jsonapi.WrapForUpdate(taID, "team-workspaces", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Patch("/team-workspaces/"+taID, body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderTeamAccess(cmd, &doc, "updated")
+}
+
+func runTeamAccessRemove(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	taID, err := resolveTeamAccessID(client, cmd, args)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Delete("/team-workspaces/" + taID); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Team access %s removed\n", taID)
+	return nil
+}
+
+func renderTeamAccess(cmd *cobra.Command, doc *jsonapi.Document, verb string) error {
+	res, err := jsonapi.ParseSingle(doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a teamAccessAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	teamID := extractRelationshipID(res, "team")
+	wsID := extractRelationshipID(res, "workspace")
+	var teamName string
+	for _, inc := range doc.Included {
+		if inc.Type == "teams" && inc.ID == teamID {
+			var t teamAttrs
+			jsonapi.UnmarshalAttributes(&inc, &t)
+			teamName = t.Name
+		}
+	}
+
+	opts := GetOutputOptions()
+
+	type taDetail struct {
+		ID          string          `json:"id"`
+		TeamID      string          `json:"team_id"`
+		TeamName    string          `json:"team_name"`
+		WorkspaceID string          `json:"workspace_id"`
+		Attrs       teamAccessAttrs `json:"attributes"`
+	}
+	data := taDetail{ID: res.ID, TeamID: teamID, TeamName: teamName, WorkspaceID: wsID, Attrs: a}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Team", defaultStr(teamName, teamID)},
+			{"Workspace", wsID},
+			{"Access", a.Access},
+			{"Runs", a.Runs},
+			{"Variables", a.Variables},
+			{"State Versions", a.StateVersions},
+			{"Sentinel Mocks", a.SentinelMocks},
+			{"Workspace Locking", boolStr(a.WorkspaceLocking)},
+			{"Run Tasks", boolStr(a.RunTasks)},
+		},
+	}
+
+	if verb != "" {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Team access %s %s\n", res.ID, verb)
+	}
+	return output.RenderTable(td, data, opts)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestTeamAccessAdd_CustomWithNames(t *testing.T) {
+	var capturedBody map[string]interface{}
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/workspaces/prod-app":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"id": "ws-prod", "type": "workspaces"},
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/teams":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"id": "team-ops", "type": "teams", "attributes": map[string]interface{}{"name": "ops"}},
+				},
+			})
+		case r.Method == "POST" && r.URL.Path == "/api/v2/team-workspaces":
+			json.NewDecoder(r.Body).Decode(&capturedBody)
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":         "tws-abc",
+					"type":       "team-workspaces",
+					"attributes": map[string]interface{}{"access": "custom", "runs": "apply"},
+				},
+			})
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"team-access", "add", "--org", "test-org", "--workspace", "prod-app", "--team", "ops",
+		"--runs", "apply", "--variables", "read", "--workspace-locking", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data := capturedBody["data"].(map[string]interface{})
+	attrs := data["attributes"].(map[string]interface{})
+	if attrs["access"] != "custom" {
+		t.Errorf("expected granular flags to imply access custom, got %v", attrs["access"])
+	}
+	if attrs["runs"] != "apply" || attrs["variables"] != "read" || attrs["workspace-locking"] != true {
+		t.Errorf("unexpected permissions: %v", attrs)
+	}
+	if _, ok := attrs["state-versions"]; ok {
+		t.Errorf("unset permissions should not be sent: %v", attrs)
+	}
+
+	rels := data["relationships"].(map[string]interface{})
+	team := rels["team"].(map[string]interface{})["data"].(map[string]interface{})
+	ws := rels["workspace"].(map[string]interface{})["data"].(map[string]interface{})
+	if team["id"] != "team-ops" || ws["id"] != "ws-prod" {
+		t.Errorf("expected resolved team and workspace ids, got team=%v ws=%v", team["id"], ws["id"])
+	}
+}
+
+func TestTeamAccessAdd_RejectsMixedAccess(t *testing.T) {
+	t.Setenv("TFC_TOKEN", "test-token")
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"team-access", "add", "--workspace", "ws-abc", "--team", "team-abc",
+		"--access", "write", "--runs", "plan"})
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected error when combining --access write with granular permissions")
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"fmt"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var teamMembersCmd = &cobra.Command{
+	Use:   "members",
+	Short: "Manage team membership",
+	Long: `Manage team membership.
+
+Users are given by username, email or organization membership ID (ou-...).
+Members are added through their organization membership, so users who have
+been invited but not yet accepted can be added too.`,
+}
+
+var teamMembersListCmd = &cobra.Command{
+	Use:   "list [team]",
+	Short: "List the members of a team",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTeamMembersList,
+}
+
+var teamMembersAddCmd = &cobra.Command{
+	Use:   "add [team] [user...]",
+	Short: "Add users to a team",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runTeamMembersAdd,
+}
+
+var teamMembersRemoveCmd = &cobra.Command{
+	Use:   "remove [team] [user...]",
+	Short: "Remove users from a team",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runTeamMembersRemove,
+}
+
+func init() {
+	teamMembersCmd.AddCommand(teamMembersListCmd, teamMembersAddCmd, teamMembersRemoveCmd)
+	teamCmd.AddCommand(teamMembersCmd)
+}
+
+// teamMember is one row of a team's membership.
+type teamMember struct {
+	MembershipID string `json:"membership_id"`
+	UserID       string `json:"user_id"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	Status       string `json:"status"`
+}
+
+func runTeamMembersList(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	teamID, err := resolveTeamID(args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Get("/teams/"+teamID+"?include=users,organization-memberships", &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	opts := GetOutputOptions()
+
+	usernames := map[string]string{}
+	var members []teamMember
+	for _, inc := range doc.Included {
+		if inc.Type == "users" {
+			var u struct {
+				Username string `json:"username"`
+			}
+			jsonapi.UnmarshalAttributes(&inc, &u)
+			usernames[inc.ID] = u.Username
+		}
+	}
+	for _, inc := range doc.Included {
+		if inc.Type != "organization-memberships" {
+			continue
+		}
+		var m struct {
+			Email  string `json:"email"`
+			Status string `json:"status"`
+		}
+		jsonapi.UnmarshalAttributes(&inc, &m)
+		userID := extractRelationshipID(&inc, "user")
+		members = append(members, teamMember{
+			MembershipID: inc.ID,
+			UserID:       userID,
+			Username:     usernames[userID],
+			Email:        m.Email,
+			Status:       m.Status,
+		})
+	}
+
+	td := output.TableData{
+		Headers: []string{"MEMBERSHIP ID", "USERNAME", "EMAIL", "STATUS"},
+	}
+	for _, m := range members {
+		td.Rows = append(td.Rows, []string{
+			m.MembershipID, defaultStr(m.Username, "-"), defaultStr(m.Email, "-"), m.Status,
+		})
+	}
+
+	return output.RenderTable(td, members, opts)
+}
+
+func runTeamMembersAdd(cmd *cobra.Command, args []string) error {
+	return changeTeamMembers(cmd, args, true)
+}
+
+func runTeamMembersRemove(cmd *cobra.Command, args []string) error {
+	return changeTeamMembers(cmd, args, false)
+}
+
+// changeTeamMembers adds or removes the users in args[1:] from the team in args[0].
+func changeTeamMembers(cmd *cobra.Command, args []string, add bool) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	teamID, err := resolveTeamID(args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var ids []string
+	for _, user := range args[1:] {
+		id, err := resolveMembershipID(user)
+		if err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		ids = append(ids, id)
+	}
+
+	path := fmt.Sprintf("/teams/%s/relationships/organization-memberships", teamID)
+	body := relationshipData("organization-memberships", ids)
+	if add {
+		err = client.Post(path, body, nil)
+	} else {
+		err = client.DeleteWithBody(path, body)
+	}
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if add {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Added %d member(s) to team %s\n", len(ids), teamID)
+	} else {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Removed %d member(s) from team %s\n", len(ids), teamID)
+	}
+	return nil
+}
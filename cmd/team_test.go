@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30d", 30 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"d", 0, true},
+		{"-1d", 0, true},
+		{"soon", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDuration(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTokenExpiry(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	got, err := tokenExpiry("30d", "", now)
+	if err != nil || !got.Equal(now.AddDate(0, 0, 30)) {
+		t.Errorf("expires-in 30d = %v, %v", got, err)
+	}
+	got, err = tokenExpiry("", "2024-06-01", now)
+	if err != nil || !got.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expires-at date = %v, %v", got, err)
+	}
+	if got, err := tokenExpiry("", "", now); err != nil || !got.IsZero() {
+		t.Errorf("no flags should mean no expiry, got %v, %v", got, err)
+	}
+	if _, err := tokenExpiry("30d", "2024-06-01", now); err == nil {
+		t.Error("expected error when both flags are set")
+	}
+	if _, err := tokenExpiry("", "2024-01-01", now); err == nil {
+		t.Error("expected error for an expiry in the past")
+	}
+}
+
+func TestTokenLifetime(t *testing.T) {
+	a := teamTokenAttrs{CreatedAt: "2024-01-01T00:00:00Z", ExpiredAt: "2024-01-31T00:00:00Z"}
+	if got := tokenLifetime(a); got != 30*24*time.Hour {
+		t.Errorf("tokenLifetime = %v, want 720h", got)
+	}
+	if got := tokenLifetime(teamTokenAttrs{CreatedAt: "2024-01-01T00:00:00Z"}); got != 0 {
+		t.Errorf("token without expiry should have no lifetime, got %v", got)
+	}
+}
+
+func TestTeamMembersAdd_ResolvesEmailAndUsername(t *testing.T) {
+	var capturedBody map[string]interface{}
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/teams":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"id": "team-ops", "type": "teams", "attributes": map[string]interface{}{"name": "ops"}},
+				},
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/organization-memberships":
+			user := func(id string) map[string]interface{} {
+				return map[string]interface{}{"data": map[string]interface{}{"type": "users", "id": id}}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"id": "ou-alice", "type": "organization-memberships",
+						"attributes":    map[string]interface{}{"email": "alice@example.com", "status": "active"},
+						"relationships": map[string]interface{}{"user": user("user-alice")}},
+					map[string]interface{}{"id": "ou-bob", "type": "organization-memberships",
+						"attributes":    map[string]interface{}{"email": "bob@example.com", "status": "active"},
+						"relationships": map[string]interface{}{"user": user("user-bob")}},
+				},
+				"included": []interface{}{
+					map[string]interface{}{"id": "user-alice", "type": "users", "attributes": map[string]interface{}{"username": "alice"}},
+					map[string]interface{}{"id": "user-bob", "type": "users", "attributes": map[string]interface{}{"username": "bob"}},
+				},
+			})
+		case r.Method == "POST" && r.URL.Path == "/api/v2/teams/team-ops/relationships/organization-memberships":
+			json.NewDecoder(r.Body).Decode(&capturedBody)
+			w.WriteHeader(204)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"team", "members", "add", "--org", "test-org", "ops", "Alice@Example.com", "bob"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := capturedBody["data"].([]interface{})
+	if len(data) != 2 {
+		t.Fatalf("expected 2 memberships in body, got %v", capturedBody)
+	}
+	for i, want := range []string{"ou-alice", "ou-bob"} {
+		m := data[i].(map[string]interface{})
+		if m["id"] != want || m["type"] != "organization-memberships" {
+			t.Errorf("membership %d = %v, want %s", i, m, want)
+		}
+	}
+}
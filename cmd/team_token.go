@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var teamTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage a team's API token",
+	Long: `Manage a team's API token.
+
+A team has a single API token. The secret is only returned when the token is
+created or rotated, so store it straight away.`,
+}
+
+var teamTokenCreateCmd = &cobra.Command{
+	Use:   "create [team]",
+	Short: "Create the team API token",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTeamTokenCreate,
+}
+
+var teamTokenRotateCmd = &cobra.Command{
+	Use:   "rotate [team]",
+	Short: "Replace the team API token with a new one",
+	Long: `Replace the team API token with a new one.
+
+The old token stops working immediately. Without --expires-in or --expires-at,
+the new token gets the same lifetime the old one had.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTeamTokenRotate,
+}
+
+var teamTokenDeleteCmd = &cobra.Command{
+	Use:   "delete [team]",
+	Short: "Delete the team API token",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTeamTokenDelete,
+}
+
+func init() {
+	for _, c := range []*cobra.Command{teamTokenCreateCmd, teamTokenRotateCmd} {
+		c.Flags().String("expires-in", "", "Token lifetime, e.g. 30d or 720h")
+		c.Flags().String("expires-at", "", "Token expiry as a date (2006-01-02) or RFC3339 timestamp")
+	}
+
+	teamTokenCmd.AddCommand(teamTokenCreateCmd, teamTokenRotateCmd, teamTokenDeleteCmd)
+	teamCmd.AddCommand(teamTokenCmd)
+}
+
+type teamTokenAttrs struct {
+	Token      string `json:"token"`
+	CreatedAt  string `json:"created-at"`
+	LastUsedAt string `json:"last-used-at"`
+	ExpiredAt  string `json:"expired-at"`
+}
+
+// tokenExpiry works out a token expiry from --expires-in or --expires-at.
+// It returns the zero time when neither is set.
+func tokenExpiry(expiresIn, expiresAt string, now time.Time) (time.Time, error) {
+	switch {
+	case expiresIn != "" && expiresAt != "":
+		return time.Time{}, output.NewUsageError("--expires-in and --expires-at are mutually exclusive")
+	case expiresIn != "":
+		d, err := parseDuration(expiresIn)
+		if err != nil || d == 0 {
+			return time.Time{}, output.NewUsageError(fmt.Sprintf("invalid --expires-in %q", expiresIn))
+		}
+		return now.Add(d).UTC(), nil
+	case expiresAt != "":
+		t, err := time.Parse(time.RFC3339, expiresAt)
+		if err != nil {
+			t, err = time.Parse("2006-01-02", expiresAt)
+		}
+		if err != nil {
+			return time.Time{}, output.NewUsageError(fmt.Sprintf("invalid --expires-at %q: use 2006-01-02 or RFC3339", expiresAt))
+		}
+		if !t.After(now) {
+			return time.Time{}, output.NewUsageError("--expires-at must be in the future")
+		}
+		return t.UTC(), nil
+	}
+	return time.Time{}, nil
+}
+
+// tokenLifetime returns how long an existing token was valid for, or zero if
+// it never expires or the timestamps cannot be parsed.
+func tokenLifetime(a teamTokenAttrs) time.Duration {
+	created, err := time.Parse(time.RFC3339, a.CreatedAt)
+	if err != nil {
+		return 0
+	}
+	expired, err := time.Parse(time.RFC3339, a.ExpiredAt)
+	if err != nil || !expired.After(created) {
+		return 0
+	}
+	return expired.Sub(created)
+}
+
+func runTeamTokenCreate(cmd *cobra.Command, args []string) error {
+	expiresIn, _ := cmd.Flags().GetString("expires-in")
+	expiresAt, _ := cmd.Flags().GetString("expires-at")
+	expiry, err := tokenExpiry(expiresIn, expiresAt, time.Now())
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	teamID, err := resolveTeamID(args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if _, err := getTeamToken(client, teamID); err == nil {
+		return output.NewUsageError(fmt.Sprintf("team %s already has a token; use \"tfc team token rotate\" to replace it", teamID))
+	} else if !api.IsNotFound(err) {
+		return output.NewAPIError(err.Error())
+	}
+
+	return generateTeamToken(cmd, client, teamID, expiry, "created")
+}
+
+func runTeamTokenRotate(cmd *cobra.Command, args []string) error {
+	expiresIn, _ := cmd.Flags().GetString("expires-in")
+	expiresAt, _ := cmd.Flags().GetString("expires-at")
+	now := time.Now()
+	expiry, err := tokenExpiry(expiresIn, expiresAt, now)
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	teamID, err := resolveTeamID(args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	current, err := getTeamToken(client, teamID)
+	if api.IsNotFound(err) {
+		return output.NewNotFoundError(fmt.Sprintf("team %s has no token; use \"tfc team token create\"", teamID))
+	} else if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if expiry.IsZero() {
+		if lifetime := tokenLifetime(current); lifetime > 0 {
+			expiry = now.Add(lifetime).UTC()
+		}
+	}
+
+	return generateTeamToken(cmd, client, teamID, expiry, "rotated")
+}
+
+func runTeamTokenDelete(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	teamID, err := resolveTeamID(args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if err := client.Delete("/teams/" + teamID + "/authentication-token"); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Team token for %s deleted\n", teamID)
+	return nil
+}
+
+// getTeamToken returns the team's current token metadata.
+func getTeamToken(client *api.Client, teamID string) (teamTokenAttrs, error) {
+	var a teamTokenAttrs
+	var doc jsonapi.Document
+	if err := client.Get("/teams/"+teamID+"/authentication-token", &doc); err != nil {
+		return a, err
+	}
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return a, err
+	}
+	jsonapi.UnmarshalAttributes(res, &a)
+	return a, nil
+}
+
+// generateTeamToken creates a new team token, replacing any existing one, and
+// prints it.
+func generateTeamToken(cmd *cobra.Command, client *api.Client, teamID string, expiry time.Time, verb string) error {
+	attrs := map[string]interface{}{}
+	if !expiry.IsZero() {
+		attrs["expired-at"] = expiry.Format(time.RFC3339)
+	}
+
+	body, err := jsonapi.WrapForCreate("authentication-token", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post("/teams/"+teamID+"/authentication-token", body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a teamTokenAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	opts := GetOutputOptions()
+
+	type tokenDetail struct {
+		ID     string         `json:"id"`
+		TeamID string         `json:"team_id"`
+		Attrs  teamTokenAttrs `json:"attributes"`
+	}
+	data := tokenDetail{ID: res.ID, TeamID: teamID, Attrs: a}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Team", teamID},
+			{"Token", a.Token},
+			{"Created", a.CreatedAt},
+			{"Expires", defaultStr(a.ExpiredAt, "never")},
+		},
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Team token for %s %s; it will not be shown again\n", teamID, verb)
+	return output.RenderTable(td, data, opts)
+}
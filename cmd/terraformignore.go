@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// defaultTerraformIgnore is always excluded from configuration uploads,
+// before any .terraformignore rules.
+var defaultTerraformIgnore = []string{".git/", ".terraform/"}
+
+// ignoreRule is one .terraformignore pattern.
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules is a parsed .terraformignore. Patterns follow .gitignore
+// syntax: "#" comments, "!" negation, a trailing "/" for directories only,
+// and "*", "?" and "**" wildcards. Patterns without a slash match at any
+// depth; others are relative to the configuration root. The last matching
+// pattern wins.
+type ignoreRules []ignoreRule
+
+// parseIgnoreRules parses .terraformignore content.
+func parseIgnoreRules(r io.Reader) (ignoreRules, error) {
+	var rules ignoreRules
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+
+		expr := globToRegexp(line)
+		if !anchored {
+			expr = "(.*/)?" + expr
+		}
+		re, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, err
+		}
+		rule.re = re
+		rules = append(rules, rule)
+	}
+	return rules, sc.Err()
+}
+
+// globToRegexp translates a .gitignore-style glob into a regular expression.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// excluded reports whether the slash-separated relative path is ignored.
+func (rules ignoreRules) excluded(rel string, isDir bool) bool {
+	excluded := false
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(rel) {
+			excluded = !rule.negate
+		}
+	}
+	return excluded
+}
+
+// loadTerraformIgnore returns the default exclusions followed by the rules
+// in dir/.terraformignore, if there is one.
+func loadTerraformIgnore(dir string) (ignoreRules, error) {
+	rules, err := parseIgnoreRules(strings.NewReader(strings.Join(defaultTerraformIgnore, "\n")))
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(dir, ".terraformignore"))
+	if errors.Is(err, os.ErrNotExist) {
+		return rules, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	custom, err := parseIgnoreRules(f)
+	if err != nil {
+		return nil, err
+	}
+	return append(rules, custom...), nil
+}
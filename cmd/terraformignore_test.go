@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	rules, err := parseIgnoreRules(strings.NewReader(`
+# comment
+.git/
+.terraform/
+*.tfstate
+/build
+docs/**/*.png
+logs/
+!keep.tfstate
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{".git", true, true},
+		{"modules/vpc/.terraform", true, true},
+		{".terraform", false, false},
+		{"terraform.tfstate", false, true},
+		{"envs/prod/terraform.tfstate", false, true},
+		{"keep.tfstate", false, false},
+		{"build", true, true},
+		{"modules/build", true, false},
+		{"docs/img/a.png", false, true},
+		{"docs/a.png", false, true},
+		{"docs/a.md", false, false},
+		{"logs", true, true},
+		{"logs", false, false},
+		{"main.tf", false, false},
+	}
+	for _, tt := range tests {
+		if got := rules.excluded(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("excluded(%q, dir=%v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
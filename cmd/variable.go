@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
 	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
 	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
 	"github.com/spf13/cobra"
@@ -94,6 +95,54 @@ type varAttrs struct {
 	CreatedAt   string `json:"created-at"`
 }
 
+// varRecord is a variable flattened together with where it was defined.
+// Source is "workspace" for workspace variables, or the variable set name.
+type varRecord struct {
+	ID          string `json:"id"`
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	HCL         bool   `json:"hcl"`
+	Sensitive   bool   `json:"sensitive"`
+	Source      string `json:"source"`
+	SourceID    string `json:"source_id"`
+}
+
+// toVarRecords converts var resources into records tagged with a source.
+func toVarRecords(resources []jsonapi.Resource, source, sourceID string) []varRecord {
+	var records []varRecord
+	for _, r := range resources {
+		var a varAttrs
+		jsonapi.UnmarshalAttributes(&r, &a)
+		records = append(records, varRecord{
+			ID:          r.ID,
+			Key:         a.Key,
+			Value:       a.Value,
+			Description: a.Description,
+			Category:    a.Category,
+			HCL:         a.HCL,
+			Sensitive:   a.Sensitive,
+			Source:      source,
+			SourceID:    sourceID,
+		})
+	}
+	return records
+}
+
+// fetchWorkspaceVars returns the variables defined directly on a workspace.
+func fetchWorkspaceVars(client *api.Client, wsID string) ([]varRecord, error) {
+	var doc jsonapi.Document
+	if err := client.Get(fmt.Sprintf("/workspaces/%s/vars", wsID), &doc); err != nil {
+		return nil, err
+	}
+	resources, err := jsonapi.ParseList(&doc)
+	if err != nil {
+		return nil, err
+	}
+	return toVarRecords(resources, "workspace", wsID), nil
+}
+
 func runVariableList(cmd *cobra.Command, args []string) error {
 	client, err := newClient()
 	if err != nil {
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var variableDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare variables between two workspaces",
+	Long: `Compare variables between two workspaces.
+
+Variables are matched by category and key. A variable is "missing" when it
+exists only in --from, "extra" when it exists only in --to, and "different"
+when its value, HCL flag or sensitive flag differ. Sensitive values cannot be
+read back from the API, so they are never compared.
+
+--sync-file writes the --from definitions of missing and different variables
+in the same JSON format as "var export --format json".`,
+	RunE: runVariableDiff,
+}
+
+func init() {
+	variableDiffCmd.Flags().String("from", "", "Source workspace name or ID (required)")
+	variableDiffCmd.Flags().String("to", "", "Target workspace name or ID (required)")
+	variableDiffCmd.Flags().Bool("all", false, "Also list variables that are identical")
+	variableDiffCmd.Flags().String("sync-file", "", "Write a JSON file of variables needed to reconcile --to with --from")
+
+	variableCmd.AddCommand(variableDiffCmd)
+}
+
+// Variable diff statuses.
+const (
+	diffSame      = "same"
+	diffMissing   = "missing"
+	diffExtra     = "extra"
+	diffDifferent = "different"
+)
+
+// varDiff describes how one key/category compares between two workspaces.
+type varDiff struct {
+	Key      string     `json:"key"`
+	Category string     `json:"category"`
+	Status   string     `json:"status"`
+	Fields   []string   `json:"fields"`
+	From     *varRecord `json:"from"`
+	To       *varRecord `json:"to"`
+}
+
+// diffVars compares two sets of variables keyed by category and key.
+func diffVars(from, to []varRecord) []varDiff {
+	index := func(vars []varRecord) map[string]varRecord {
+		m := make(map[string]varRecord, len(vars))
+		for _, v := range vars {
+			m[v.Category+"/"+v.Key] = v
+		}
+		return m
+	}
+	fromIdx, toIdx := index(from), index(to)
+
+	var result []varDiff
+	for id, f := range fromIdx {
+		t, ok := toIdx[id]
+		if !ok {
+			result = append(result, varDiff{Key: f.Key, Category: f.Category, Status: diffMissing, From: &f})
+			continue
+		}
+		d := varDiff{Key: f.Key, Category: f.Category, Status: diffSame, From: &f, To: &t}
+		if f.HCL != t.HCL {
+			d.Fields = append(d.Fields, "hcl")
+		}
+		if f.Sensitive != t.Sensitive {
+			d.Fields = append(d.Fields, "sensitive")
+		}
+		if !f.Sensitive && !t.Sensitive && f.Value != t.Value {
+			d.Fields = append(d.Fields, "value")
+		}
+		if len(d.Fields) > 0 {
+			d.Status = diffDifferent
+		}
+		result = append(result, d)
+	}
+	for id, t := range toIdx {
+		if _, ok := fromIdx[id]; !ok {
+			result = append(result, varDiff{Key: t.Key, Category: t.Category, Status: diffExtra, To: &t})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Category != result[j].Category {
+			return result[i].Category == "terraform"
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+func runVariableDiff(cmd *cobra.Command, args []string) error {
+	from, _ := cmd.Flags().GetString("from")
+	to, _ := cmd.Flags().GetString("to")
+	showAll, _ := cmd.Flags().GetBool("all")
+	syncFile, _ := cmd.Flags().GetString("sync-file")
+
+	if from == "" || to == "" {
+		return output.NewUsageError("--from and --to are required")
+	}
+
+	fromID, err := resolveWorkspaceID(from)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	toID, err := resolveWorkspaceID(to)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	fromVars, err := fetchWorkspaceVars(client, fromID)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	toVars, err := fetchWorkspaceVars(client, toID)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	diffs := diffVars(fromVars, toVars)
+
+	if syncFile != "" {
+		var reconcile []varRecord
+		for _, d := range diffs {
+			if d.Status == diffMissing || d.Status == diffDifferent {
+				reconcile = append(reconcile, *d.From)
+			}
+		}
+		vars, _ := selectExportVars(reconcile, "json", false)
+		raw, err := json.MarshalIndent(vars, "", "  ")
+		if err != nil {
+			return output.NewInternalError(fmt.Sprintf("json marshal: %v", err))
+		}
+		if err := os.WriteFile(syncFile, append(raw, '\n'), 0o644); err != nil {
+			return output.NewInternalError(fmt.Sprintf("write sync file: %v", err))
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %d variables to %s\n", len(vars), syncFile)
+	}
+
+	opts := GetOutputOptions()
+
+	var shown []varDiff
+	td := output.TableData{
+		Headers: []string{"KEY", "CATEGORY", "STATUS", "FROM", "TO", "DIFFERS"},
+	}
+	counts := map[string]int{}
+	for _, d := range diffs {
+		counts[d.Status]++
+		if d.Status == diffSame && !showAll {
+			continue
+		}
+		shown = append(shown, d)
+		td.Rows = append(td.Rows, []string{
+			d.Key, d.Category, d.Status,
+			truncateStr(diffValue(d.From), 30), truncateStr(diffValue(d.To), 30),
+			defaultStr(strings.Join(d.Fields, ", "), "-"),
+		})
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "%d missing, %d extra, %d different, %d same\n",
+		counts[diffMissing], counts[diffExtra], counts[diffDifferent], counts[diffSame])
+
+	return output.RenderTable(td, shown, opts)
+}
+
+// diffValue renders one side of a diff for the table.
+func diffValue(v *varRecord) string {
+	switch {
+	case v == nil:
+		return "-"
+	case v.Sensitive:
+		return "(sensitive)"
+	default:
+		return v.Value
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import "testing"
+
+func TestDiffVars(t *testing.T) {
+	from := []varRecord{
+		{Key: "region", Value: "us-east-1", Category: "terraform"},
+		{Key: "instance_type", Value: "t3.small", Category: "terraform"},
+		{Key: "tags", Value: `{a = 1}`, Category: "terraform", HCL: true},
+		{Key: "db_password", Category: "terraform", Sensitive: true},
+		{Key: "only_staging", Value: "x", Category: "terraform"},
+	}
+	to := []varRecord{
+		{Key: "region", Value: "us-east-1", Category: "terraform"},
+		{Key: "instance_type", Value: "m5.large", Category: "terraform"},
+		{Key: "tags", Value: `{a = 1}`, Category: "terraform"},
+		{Key: "db_password", Category: "terraform", Sensitive: true},
+		{Key: "only_staging", Value: "x", Category: "env"},
+	}
+
+	got := map[string]varDiff{}
+	for _, d := range diffVars(from, to) {
+		got[d.Category+"/"+d.Key] = d
+	}
+
+	tests := []struct {
+		id     string
+		status string
+		fields []string
+	}{
+		{"terraform/region", diffSame, nil},
+		{"terraform/instance_type", diffDifferent, []string{"value"}},
+		{"terraform/tags", diffDifferent, []string{"hcl"}},
+		{"terraform/db_password", diffSame, nil},
+		{"terraform/only_staging", diffMissing, nil},
+		{"env/only_staging", diffExtra, nil},
+	}
+	for _, tt := range tests {
+		d, ok := got[tt.id]
+		if !ok {
+			t.Errorf("%s: missing from diff", tt.id)
+			continue
+		}
+		if d.Status != tt.status {
+			t.Errorf("%s: status = %s, want %s", tt.id, d.Status, tt.status)
+		}
+		if len(d.Fields) != len(tt.fields) || (len(tt.fields) > 0 && d.Fields[0] != tt.fields[0]) {
+			t.Errorf("%s: fields = %v, want %v", tt.id, d.Fields, tt.fields)
+		}
+	}
+	if len(got) != len(tests) {
+		t.Errorf("expected %d diff entries, got %d", len(tests), len(got))
+	}
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+var variableEffectiveCmd = &cobra.Command{
+	Use:   "effective",
+	Short: "Show the value a run will see for each variable",
+	Long: `Show the value a run will see for each variable.
+
+Combines workspace variables with every variable set applied to the workspace
+and resolves them using Terraform Cloud precedence, highest first:
+
+  1. priority global variable sets
+  2. priority project-scoped variable sets
+  3. priority workspace-scoped variable sets
+  4. workspace variables
+  5. workspace-scoped variable sets
+  6. project-scoped variable sets
+  7. global variable sets
+
+Within a level, the variable set whose name sorts first wins.`,
+	RunE: runVariableEffective,
+}
+
+func init() {
+	variableEffectiveCmd.Flags().String("workspace", "", "Workspace name or ID (required)")
+
+	variableCmd.AddCommand(variableEffectiveCmd)
+}
+
+// Variable scopes, as reported by var effective.
+const (
+	scopeWorkspaceVar = "workspace"
+	scopeVarsetWS     = "varset:workspace"
+	scopeVarsetProj   = "varset:project"
+	scopeVarsetGlobal = "varset:global"
+)
+
+// scopedVar is a variable definition together with the scope it applies from.
+type scopedVar struct {
+	varRecord
+	Scope    string `json:"scope"`
+	Priority bool   `json:"priority"`
+}
+
+// effectiveVar is the winning definition for a key/category plus the
+// definitions it shadows, highest precedence first.
+type effectiveVar struct {
+	Key       string      `json:"key"`
+	Category  string      `json:"category"`
+	Value     string      `json:"value"`
+	HCL       bool        `json:"hcl"`
+	Sensitive bool        `json:"sensitive"`
+	Source    string      `json:"source"`
+	SourceID  string      `json:"source_id"`
+	Scope     string      `json:"scope"`
+	Priority  bool        `json:"priority"`
+	Shadowed  []scopedVar `json:"shadowed"`
+}
+
+// precedenceRank orders definitions; lower ranks win.
+func precedenceRank(v scopedVar) int {
+	if v.Priority {
+		switch v.Scope {
+		case scopeVarsetGlobal:
+			return 0
+		case scopeVarsetProj:
+			return 1
+		default:
+			return 2
+		}
+	}
+	switch v.Scope {
+	case scopeWorkspaceVar:
+		return 3
+	case scopeVarsetWS:
+		return 4
+	case scopeVarsetProj:
+		return 5
+	default:
+		return 6
+	}
+}
+
+// lessByPrecedence reports whether a wins over b, breaking ties in
+// precedence by variable set name.
+func lessByPrecedence(a, b scopedVar) bool {
+	ra, rb := precedenceRank(a), precedenceRank(b)
+	if ra != rb {
+		return ra < rb
+	}
+	return a.Source < b.Source
+}
+
+// resolveEffectiveVars groups definitions by category and key and picks the
+// winner of each group by precedence, breaking ties by variable set name.
+func resolveEffectiveVars(defs []scopedVar) []effectiveVar {
+	groups := map[string][]scopedVar{}
+	var order []string
+	for _, d := range defs {
+		id := d.Category + "/" + d.Key
+		if _, ok := groups[id]; !ok {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], d)
+	}
+
+	var result []effectiveVar
+	for _, id := range order {
+		g := groups[id]
+		sort.SliceStable(g, func(i, j int) bool { return lessByPrecedence(g[i], g[j]) })
+		w := g[0]
+		result = append(result, effectiveVar{
+			Key:       w.Key,
+			Category:  w.Category,
+			Value:     w.Value,
+			HCL:       w.HCL,
+			Sensitive: w.Sensitive,
+			Source:    w.Source,
+			SourceID:  w.SourceID,
+			Scope:     w.Scope,
+			Priority:  w.Priority,
+			Shadowed:  g[1:],
+		})
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Category != result[j].Category {
+			return result[i].Category == "terraform"
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// varsetScope reports how a variable set reaches a workspace.
+func varsetScope(vs *jsonapi.Resource, global bool, wsID, projectID string) string {
+	if global {
+		return scopeVarsetGlobal
+	}
+	for _, id := range extractRelationshipIDs(vs, "workspaces") {
+		if id == wsID {
+			return scopeVarsetWS
+		}
+	}
+	for _, id := range extractRelationshipIDs(vs, "projects") {
+		if id == projectID {
+			return scopeVarsetProj
+		}
+	}
+	return scopeVarsetWS
+}
+
+// fetchScopedVars returns a workspace's variables and those of every
+// variable set applied to it, each with the scope it applies from.
+func fetchScopedVars(client *api.Client, wsID string) ([]scopedVar, error) {
+	var wsDoc jsonapi.Document
+	if err := client.Get("/workspaces/"+wsID, &wsDoc); err != nil {
+		return nil, err
+	}
+	ws, err := jsonapi.ParseSingle(&wsDoc)
+	if err != nil {
+		return nil, err
+	}
+	projectID := extractRelationshipID(ws, "project")
+
+	wsVars, err := fetchWorkspaceVars(client, wsID)
+	if err != nil {
+		return nil, err
+	}
+	var defs []scopedVar
+	for _, v := range wsVars {
+		defs = append(defs, scopedVar{varRecord: v, Scope: scopeWorkspaceVar})
+	}
+
+	varsets, err := fetchWorkspaceVarsets(client, wsID)
+	if err != nil {
+		return nil, err
+	}
+	for _, vs := range varsets {
+		var a varsetAttrs
+		jsonapi.UnmarshalAttributes(&vs, &a)
+		scope := varsetScope(&vs, a.Global, wsID, projectID)
+
+		vars, err := fetchVarsetVars(client, vs)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vars {
+			defs = append(defs, scopedVar{varRecord: v, Scope: scope, Priority: a.Priority})
+		}
+	}
+
+	return defs, nil
+}
+
+// varsByPrecedence returns the definitions' records ordered as
+// resolveEffectiveVars ranks them, so the first record for each key wins.
+func varsByPrecedence(defs []scopedVar) []varRecord {
+	sorted := append([]scopedVar(nil), defs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return lessByPrecedence(sorted[i], sorted[j]) })
+	records := make([]varRecord, len(sorted))
+	for i, d := range sorted {
+		records[i] = d.varRecord
+	}
+	return records
+}
+
+func runVariableEffective(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	if workspace == "" {
+		return output.NewUsageError("--workspace is required")
+	}
+
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	defs, err := fetchScopedVars(client, wsID)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	effective := resolveEffectiveVars(defs)
+
+	opts := GetOutputOptions()
+
+	td := output.TableData{
+		Headers: []string{"KEY", "CATEGORY", "VALUE", "SOURCE", "SCOPE", "SHADOWS"},
+	}
+	for _, e := range effective {
+		value := e.Value
+		if e.Sensitive {
+			value = "(sensitive)"
+		}
+		scope := e.Scope
+		if e.Priority {
+			scope += " (priority)"
+		}
+		var shadowed []string
+		for _, s := range e.Shadowed {
+			shadowed = append(shadowed, fmt.Sprintf("%s [%s]", s.Source, s.Scope))
+		}
+		td.Rows = append(td.Rows, []string{
+			e.Key, e.Category, truncateStr(value, 40), e.Source, scope,
+			defaultStr(strings.Join(shadowed, ", "), "-"),
+		})
+	}
+
+	return output.RenderTable(td, effective, opts)
+}
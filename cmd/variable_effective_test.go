@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+)
+
+func TestResolveEffectiveVars_Precedence(t *testing.T) {
+	defs := []scopedVar{
+		{varRecord: varRecord{Key: "region", Value: "global", Category: "terraform", Source: "org-defaults"}, Scope: scopeVarsetGlobal},
+		{varRecord: varRecord{Key: "region", Value: "project", Category: "terraform", Source: "team-defaults"}, Scope: scopeVarsetProj},
+		{varRecord: varRecord{Key: "region", Value: "workspace", Category: "terraform", Source: "workspace"}, Scope: scopeWorkspaceVar},
+		{varRecord: varRecord{Key: "region", Value: "env-var", Category: "env", Source: "workspace"}, Scope: scopeWorkspaceVar},
+	}
+
+	got := resolveEffectiveVars(defs)
+	if len(got) != 2 {
+		t.Fatalf("expected 2 effective vars (terraform and env), got %d", len(got))
+	}
+	if got[0].Category != "terraform" || got[0].Value != "workspace" {
+		t.Errorf("expected workspace variable to win, got %+v", got[0])
+	}
+	if len(got[0].Shadowed) != 2 || got[0].Shadowed[0].Source != "team-defaults" || got[0].Shadowed[1].Source != "org-defaults" {
+		t.Errorf("expected project then global varset shadowed, got %+v", got[0].Shadowed)
+	}
+	if got[1].Category != "env" || len(got[1].Shadowed) != 0 {
+		t.Errorf("expected env variable with nothing shadowed, got %+v", got[1])
+	}
+}
+
+func TestResolveEffectiveVars_PriorityBeatsWorkspace(t *testing.T) {
+	defs := []scopedVar{
+		{varRecord: varRecord{Key: "tier", Value: "ws", Category: "terraform", Source: "workspace"}, Scope: scopeWorkspaceVar},
+		{varRecord: varRecord{Key: "tier", Value: "ws-prio", Category: "terraform", Source: "b-set"}, Scope: scopeVarsetWS, Priority: true},
+		{varRecord: varRecord{Key: "tier", Value: "global-prio", Category: "terraform", Source: "z-set"}, Scope: scopeVarsetGlobal, Priority: true},
+	}
+	got := resolveEffectiveVars(defs)
+	if got[0].Value != "global-prio" {
+		t.Errorf("expected priority global varset to win, got %q from %s", got[0].Value, got[0].Source)
+	}
+}
+
+func TestResolveEffectiveVars_LexicalTieBreak(t *testing.T) {
+	defs := []scopedVar{
+		{varRecord: varRecord{Key: "x", Value: "b", Category: "terraform", Source: "B_set"}, Scope: scopeVarsetGlobal},
+		{varRecord: varRecord{Key: "x", Value: "a", Category: "terraform", Source: "A_set"}, Scope: scopeVarsetGlobal},
+	}
+	got := resolveEffectiveVars(defs)
+	if got[0].Source != "A_set" {
+		t.Errorf("expected A_set to win lexical tie, got %s", got[0].Source)
+	}
+}
+
+func TestVarsetScope(t *testing.T) {
+	rels := map[string]json.RawMessage{
+		"workspaces": json.RawMessage(`{"data":[{"id":"ws-other","type":"workspaces"}]}`),
+		"projects":   json.RawMessage(`{"data":[{"id":"prj-1","type":"projects"}]}`),
+	}
+	vs := &jsonapi.Resource{ID: "varset-1", Relationships: rels}
+
+	if got := varsetScope(vs, true, "ws-1", "prj-1"); got != scopeVarsetGlobal {
+		t.Errorf("expected global scope, got %s", got)
+	}
+	if got := varsetScope(vs, false, "ws-1", "prj-1"); got != scopeVarsetProj {
+		t.Errorf("expected project scope, got %s", got)
+	}
+	if got := varsetScope(vs, false, "ws-other", "prj-1"); got != scopeVarsetWS {
+		t.Errorf("expected workspace scope, got %s", got)
+	}
+}
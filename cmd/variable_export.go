@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
+
+// sensitivePlaceholder stands in for sensitive values, which the API never returns.
+const sensitivePlaceholder = "<sensitive>"
+
+var variableExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export workspace variables to a tfvars, env, JSON or YAML file",
+	Long: `Export workspace variables to a tfvars, env, JSON or YAML file.
+
+The tfvars format contains only terraform variables and the env format only
+environment variables; json and yaml contain both. Sensitive values are never
+returned by the API, so they are written as a placeholder or skipped.
+
+Write to a file with the global --output/-o flag.`,
+	RunE: runVariableExport,
+}
+
+func init() {
+	variableExportCmd.Flags().String("workspace", "", "Workspace name or ID (required)")
+	variableExportCmd.Flags().String("format", "tfvars", "Format: tfvars, env, json, yaml")
+	variableExportCmd.Flags().String("sensitive", "placeholder", "Sensitive variables: placeholder or skip")
+	variableExportCmd.Flags().Bool("include-varsets", false, "Include variables inherited from variable sets")
+
+	variableCmd.AddCommand(variableExportCmd)
+}
+
+// exportVar is the serialized form of a variable in json and yaml exports.
+type exportVar struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Category    string `json:"category"`
+	HCL         bool   `json:"hcl"`
+	Sensitive   bool   `json:"sensitive"`
+	Description string `json:"description,omitempty"`
+	Source      string `json:"source,omitempty"`
+}
+
+func runVariableExport(cmd *cobra.Command, args []string) error {
+	workspace, _ := cmd.Flags().GetString("workspace")
+	format, _ := cmd.Flags().GetString("format")
+	sensitive, _ := cmd.Flags().GetString("sensitive")
+	includeVarsets, _ := cmd.Flags().GetBool("include-varsets")
+
+	if workspace == "" {
+		return output.NewUsageError("--workspace is required")
+	}
+	switch format {
+	case "tfvars", "env", "json", "yaml":
+	default:
+		return output.NewUsageError(fmt.Sprintf("invalid --format %q: must be tfvars, env, json or yaml", format))
+	}
+	if sensitive != "placeholder" && sensitive != "skip" {
+		return output.NewUsageError(fmt.Sprintf("invalid --sensitive %q: must be placeholder or skip", sensitive))
+	}
+
+	wsID, err := resolveWorkspaceID(workspace)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var records []varRecord
+	if includeVarsets {
+		defs, err := fetchScopedVars(client, wsID)
+		if err != nil {
+			return output.NewAPIError(err.Error())
+		}
+		records = varsByPrecedence(defs)
+	} else {
+		if records, err = fetchWorkspaceVars(client, wsID); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	}
+
+	vars, skipped := selectExportVars(records, format, sensitive == "skip")
+
+	var buf bytes.Buffer
+	switch format {
+	case "tfvars":
+		writeTFVars(&buf, vars)
+	case "env":
+		writeEnvFile(&buf, vars)
+	case "json":
+		out, err := json.MarshalIndent(vars, "", "  ")
+		if err != nil {
+			return output.NewInternalError(fmt.Sprintf("json marshal: %v", err))
+		}
+		buf.Write(out)
+		buf.WriteByte('\n')
+	case "yaml":
+		writeYAMLVars(&buf, vars)
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d variables (%d skipped)\n", len(vars), skipped)
+	return output.RenderStream(&buf, GetOutputOptions())
+}
+
+// selectExportVars filters records down to what the given format can hold and
+// returns them sorted by category and key, along with the number skipped.
+// tfvars and env can only hold one value per key, so only the first record
+// for a key is kept; records must come highest precedence first (see
+// varsByPrecedence).
+func selectExportVars(records []varRecord, format string, skipSensitive bool) ([]exportVar, int) {
+	var vars []exportVar
+	skipped := 0
+	seen := map[string]bool{}
+
+	for _, r := range records {
+		if (format == "tfvars" && r.Category != "terraform") || (format == "env" && r.Category != "env") {
+			continue
+		}
+		if r.Sensitive && skipSensitive {
+			skipped++
+			continue
+		}
+		if format == "tfvars" || format == "env" {
+			if seen[r.Key] {
+				skipped++
+				continue
+			}
+			seen[r.Key] = true
+		}
+
+		v := exportVar{
+			Key:         r.Key,
+			Value:       r.Value,
+			Category:    r.Category,
+			HCL:         r.HCL,
+			Sensitive:   r.Sensitive,
+			Description: r.Description,
+		}
+		if r.Sensitive {
+			v.Value = sensitivePlaceholder
+		}
+		if r.Source != "workspace" {
+			v.Source = r.Source
+		}
+		vars = append(vars, v)
+	}
+
+	sort.SliceStable(vars, func(i, j int) bool {
+		if vars[i].Category != vars[j].Category {
+			return vars[i].Category == "terraform"
+		}
+		return vars[i].Key < vars[j].Key
+	})
+	return vars, skipped
+}
+
+func writeTFVars(buf *bytes.Buffer, vars []exportVar) {
+	for _, v := range vars {
+		if v.Description != "" {
+			fmt.Fprintf(buf, "# %s\n", strings.ReplaceAll(v.Description, "\n", " "))
+		}
+		if v.Source != "" {
+			fmt.Fprintf(buf, "# from variable set %q\n", v.Source)
+		}
+		value := v.Value
+		if !v.HCL || v.Sensitive {
+			value = hclQuote(value)
+		}
+		if v.Sensitive {
+			fmt.Fprintf(buf, "%s = %s # sensitive\n", v.Key, value)
+		} else {
+			fmt.Fprintf(buf, "%s = %s\n", v.Key, value)
+		}
+	}
+}
+
+func writeEnvFile(buf *bytes.Buffer, vars []exportVar) {
+	for _, v := range vars {
+		if v.Source != "" {
+			fmt.Fprintf(buf, "# from variable set %q\n", v.Source)
+		}
+		fmt.Fprintf(buf, "%s=%s\n", v.Key, shellQuote(v.Value))
+	}
+}
+
+func writeYAMLVars(buf *bytes.Buffer, vars []exportVar) {
+	if len(vars) == 0 {
+		buf.WriteString("[]\n")
+		return
+	}
+	for _, v := range vars {
+		// strconv.Quote produces double-quoted scalars that YAML accepts as-is.
+		fmt.Fprintf(buf, "- key: %s\n", strconv.Quote(v.Key))
+		fmt.Fprintf(buf, "  value: %s\n", strconv.Quote(v.Value))
+		fmt.Fprintf(buf, "  category: %s\n", v.Category)
+		fmt.Fprintf(buf, "  hcl: %t\n", v.HCL)
+		fmt.Fprintf(buf, "  sensitive: %t\n", v.Sensitive)
+		if v.Description != "" {
+			fmt.Fprintf(buf, "  description: %s\n", strconv.Quote(v.Description))
+		}
+		if v.Source != "" {
+			fmt.Fprintf(buf, "  source: %s\n", strconv.Quote(v.Source))
+		}
+	}
+}
+
+// hclQuote renders s as an HCL string literal, escaping template sequences.
+func hclQuote(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+		"${", "$${",
+		"%{", "%%{",
+	)
+	return `"` + r.Replace(s) + `"`
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,-]+$`)
+
+// shellQuote single-quotes s unless it only contains shell-safe characters.
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
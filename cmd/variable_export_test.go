@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHCLQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", `"plain"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"line1\nline2", `"line1\nline2"`},
+		{"${var.x}", `"$${var.x}"`},
+		{"%{ if x }", `"%%{ if x }"`},
+		{`C:\path`, `"C:\\path"`},
+	}
+	for _, tt := range tests {
+		if got := hclQuote(tt.in); got != tt.want {
+			t.Errorf("hclQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"us-east-1", "us-east-1"},
+		{"hello world", "'hello world'"},
+		{"it's", `'it'\''s'`},
+		{"", "''"},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSelectExportVars_TFVars(t *testing.T) {
+	records := []varRecord{
+		{Key: "region", Value: "us-east-1", Category: "terraform", Source: "workspace"},
+		{Key: "AWS_REGION", Value: "us-east-1", Category: "env", Source: "workspace"},
+		{Key: "db_password", Category: "terraform", Sensitive: true, Source: "workspace"},
+		{Key: "region", Value: "eu-west-1", Category: "terraform", Source: "shared"},
+		{Key: "tags", Value: `{env = "prod"}`, Category: "terraform", HCL: true, Source: "shared"},
+	}
+
+	vars, skipped := selectExportVars(records, "tfvars", false)
+	if len(vars) != 3 {
+		t.Fatalf("expected 3 vars, got %d: %+v", len(vars), vars)
+	}
+	if skipped != 1 {
+		t.Errorf("expected 1 skipped duplicate, got %d", skipped)
+	}
+
+	var buf bytes.Buffer
+	writeTFVars(&buf, vars)
+	out := buf.String()
+
+	for _, want := range []string{
+		`db_password = "<sensitive>" # sensitive`,
+		`region = "us-east-1"`,
+		`# from variable set "shared"`,
+		`tags = {env = "prod"}`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "eu-west-1") {
+		t.Errorf("shadowed variable set value should not be exported:\n%s", out)
+	}
+}
+
+func TestSelectExportVars_SkipSensitive(t *testing.T) {
+	records := []varRecord{
+		{Key: "TOKEN", Category: "env", Sensitive: true, Source: "workspace"},
+		{Key: "DEBUG", Value: "1", Category: "env", Source: "workspace"},
+	}
+	vars, skipped := selectExportVars(records, "env", true)
+	if len(vars) != 1 || vars[0].Key != "DEBUG" {
+		t.Fatalf("expected only DEBUG, got %+v", vars)
+	}
+	if skipped != 1 {
+		t.Errorf("expected 1 skipped, got %d", skipped)
+	}
+}
+
+func TestSelectExportVars_JSONKeepsAllSources(t *testing.T) {
+	records := []varRecord{
+		{Key: "region", Value: "us-east-1", Category: "terraform", Source: "workspace"},
+		{Key: "region", Value: "eu-west-1", Category: "terraform", Source: "shared"},
+		{Key: "AWS_REGION", Value: "us-east-1", Category: "env", Source: "workspace"},
+	}
+	vars, _ := selectExportVars(records, "json", false)
+	if len(vars) != 3 {
+		t.Fatalf("expected all 3 vars, got %d", len(vars))
+	}
+	if vars[0].Category != "terraform" || vars[2].Category != "env" {
+		t.Errorf("expected terraform variables before env variables, got %+v", vars)
+	}
+	if vars[0].Source != "" || vars[1].Source != "shared" {
+		t.Errorf("expected workspace variable first with no source, got %+v", vars)
+	}
+}
+
+func TestSelectExportVars_PriorityVarsetWins(t *testing.T) {
+	defs := []scopedVar{
+		{varRecord: varRecord{Key: "region", Value: "us-east-1", Category: "terraform", Source: "workspace"}, Scope: scopeWorkspaceVar},
+		{varRecord: varRecord{Key: "region", Value: "eu-west-1", Category: "terraform", Source: "shared"}, Scope: scopeVarsetGlobal},
+		{varRecord: varRecord{Key: "region", Value: "ap-south-1", Category: "terraform", Source: "mandated"}, Scope: scopeVarsetProj, Priority: true},
+	}
+
+	vars, skipped := selectExportVars(varsByPrecedence(defs), "tfvars", false)
+	if len(vars) != 1 || skipped != 2 {
+		t.Fatalf("expected 1 var and 2 skipped, got %+v (%d skipped)", vars, skipped)
+	}
+	if vars[0].Value != "ap-south-1" || vars[0].Source != "mandated" {
+		t.Errorf("expected the priority variable set to win, got %+v", vars[0])
+	}
+	if want := resolveEffectiveVars(defs)[0].Value; vars[0].Value != want {
+		t.Errorf("export and var effective disagree: %q vs %q", vars[0].Value, want)
+	}
+}
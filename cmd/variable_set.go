@@ -1,6 +1,14 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+	"strings"
+
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/api"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/jsonapi"
+	"gitea.roboalch.com/roboalchemist/tfc/pkg/output"
+	"github.com/spf13/cobra"
+)
 
 var varsetCmd = &cobra.Command{
 	Use:     "varset",
@@ -11,78 +19,122 @@ var varsetCmd = &cobra.Command{
 var varsetListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List variable sets for an organization",
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("varset list")
-	},
+	RunE:  runVarsetList,
 }
 
 var varsetShowCmd = &cobra.Command{
-	Use:   "show [id]",
+	Use:   "show [name-or-id]",
 	Short: "Show variable set details",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("varset show")
-	},
+	RunE:  runVarsetShow,
 }
 
 var varsetCreateCmd = &cobra.Command{
 	Use:   "create [name]",
 	Short: "Create a new variable set",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("varset create")
-	},
+	RunE:  runVarsetCreate,
 }
 
 var varsetUpdateCmd = &cobra.Command{
-	Use:   "update [id]",
+	Use:   "update [name-or-id]",
 	Short: "Update a variable set",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("varset update")
-	},
+	RunE:  runVarsetUpdate,
 }
 
 var varsetDeleteCmd = &cobra.Command{
-	Use:   "delete [id]",
+	Use:   "delete [name-or-id]",
 	Short: "Delete a variable set",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("varset delete")
-	},
+	RunE:  runVarsetDelete,
 }
 
 var varsetApplyCmd = &cobra.Command{
-	Use:   "apply [id]",
-	Short: "Apply a variable set to workspaces",
+	Use:   "apply [name-or-id]",
+	Short: "Apply a variable set to workspaces and projects",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("varset apply")
-	},
+	RunE:  runVarsetApply,
 }
 
 var varsetRemoveCmd = &cobra.Command{
-	Use:   "remove [id]",
-	Short: "Remove a variable set from workspaces",
+	Use:   "remove [name-or-id]",
+	Short: "Remove a variable set from workspaces and projects",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runVarsetRemove,
+}
+
+var varsetVarCmd = &cobra.Command{
+	Use:   "var",
+	Short: "Manage variables in a variable set",
+}
+
+var varsetVarListCmd = &cobra.Command{
+	Use:   "list [varset]",
+	Short: "List variables in a variable set",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runVarsetVarList,
+}
+
+var varsetVarAddCmd = &cobra.Command{
+	Use:   "add [varset]",
+	Short: "Add a variable to a variable set",
 	Args:  cobra.ExactArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		return notImplemented("varset remove")
-	},
+	RunE:  runVarsetVarAdd,
+}
+
+var varsetVarUpdateCmd = &cobra.Command{
+	Use:   "update [varset] [var-id-or-key]",
+	Short: "Update a variable in a variable set",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runVarsetVarUpdate,
+}
+
+var varsetVarDeleteCmd = &cobra.Command{
+	Use:   "delete [varset] [var-id-or-key]",
+	Short: "Delete a variable from a variable set",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runVarsetVarDelete,
 }
 
 func init() {
 	// Create flags
 	varsetCreateCmd.Flags().String("description", "", "Description")
 	varsetCreateCmd.Flags().Bool("global", false, "Apply to all workspaces in the organization")
+	varsetCreateCmd.Flags().Bool("priority", false, "Override workspace variables and more specific variable sets")
 
 	// Update flags
 	varsetUpdateCmd.Flags().String("name", "", "New name")
 	varsetUpdateCmd.Flags().String("description", "", "Description")
 	varsetUpdateCmd.Flags().Bool("global", false, "Apply to all workspaces")
+	varsetUpdateCmd.Flags().Bool("priority", false, "Override workspace variables and more specific variable sets")
 
 	// Apply/Remove flags
-	varsetApplyCmd.Flags().StringSlice("workspace", nil, "Workspace IDs to apply to")
-	varsetRemoveCmd.Flags().StringSlice("workspace", nil, "Workspace IDs to remove from")
+	varsetApplyCmd.Flags().StringSlice("workspace", nil, "Workspace names or IDs to apply to")
+	varsetApplyCmd.Flags().StringSlice("project", nil, "Project names or IDs to apply to")
+	varsetRemoveCmd.Flags().StringSlice("workspace", nil, "Workspace names or IDs to remove from")
+	varsetRemoveCmd.Flags().StringSlice("project", nil, "Project names or IDs to remove from")
+
+	// Variable flags
+	varsetVarAddCmd.Flags().String("key", "", "Variable key (required)")
+	varsetVarAddCmd.Flags().String("value", "", "Variable value")
+	varsetVarAddCmd.Flags().String("description", "", "Variable description")
+	varsetVarAddCmd.Flags().String("category", "terraform", "Category: terraform or env")
+	varsetVarAddCmd.Flags().Bool("hcl", false, "Parse value as HCL")
+	varsetVarAddCmd.Flags().Bool("sensitive", false, "Mark as sensitive")
+
+	varsetVarUpdateCmd.Flags().String("key", "", "New variable key")
+	varsetVarUpdateCmd.Flags().String("value", "", "Variable value")
+	varsetVarUpdateCmd.Flags().String("description", "", "Variable description")
+	varsetVarUpdateCmd.Flags().Bool("hcl", false, "Parse value as HCL")
+	varsetVarUpdateCmd.Flags().Bool("sensitive", false, "Mark as sensitive")
+
+	varsetVarCmd.AddCommand(
+		varsetVarListCmd,
+		varsetVarAddCmd,
+		varsetVarUpdateCmd,
+		varsetVarDeleteCmd,
+	)
 
 	varsetCmd.AddCommand(
 		varsetListCmd,
@@ -92,6 +144,631 @@ func init() {
 		varsetDeleteCmd,
 		varsetApplyCmd,
 		varsetRemoveCmd,
+		varsetVarCmd,
 	)
 	rootCmd.AddCommand(varsetCmd)
 }
+
+type varsetAttrs struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Global         bool   `json:"global"`
+	Priority       bool   `json:"priority"`
+	VarCount       int    `json:"var-count"`
+	WorkspaceCount int    `json:"workspace-count"`
+	ProjectCount   int    `json:"project-count"`
+	UpdatedAt      string `json:"updated-at"`
+}
+
+// fetchWorkspaceVarsets returns every variable set applied to a workspace,
+// whether global, project-scoped or attached to the workspace directly.
+func fetchWorkspaceVarsets(client *api.Client, wsID string) ([]jsonapi.Resource, error) {
+	var all []jsonapi.Resource
+	path := fmt.Sprintf("/workspaces/%s/varsets?page[size]=100", wsID)
+	err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+		all = append(all, page...)
+	})
+	return all, err
+}
+
+// fetchVarsetVars returns the variables in a variable set, tagged with the set name.
+func fetchVarsetVars(client *api.Client, vs jsonapi.Resource) ([]varRecord, error) {
+	var a varsetAttrs
+	jsonapi.UnmarshalAttributes(&vs, &a)
+
+	var doc jsonapi.Document
+	if err := client.Get(fmt.Sprintf("/varsets/%s/relationships/vars", vs.ID), &doc); err != nil {
+		return nil, err
+	}
+	resources, err := jsonapi.ParseList(&doc)
+	if err != nil {
+		return nil, err
+	}
+	return toVarRecords(resources, a.Name, vs.ID), nil
+}
+
+// resolveVarsetID resolves a variable set name or ID to a variable set ID.
+// If the value starts with "varset-", it is returned as-is. Otherwise, the
+// organization's variable sets are searched by exact name.
+func resolveVarsetID(client *api.Client, varset string) (string, error) {
+	if strings.HasPrefix(varset, "varset-") {
+		return varset, nil
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return "", err
+	}
+
+	var found string
+	path := fmt.Sprintf("/organizations/%s/varsets?page[size]=100", org)
+	err = client.GetAllPages(path, func(page []jsonapi.Resource) {
+		for _, r := range page {
+			var a varsetAttrs
+			jsonapi.UnmarshalAttributes(&r, &a)
+			if a.Name == varset && found == "" {
+				found = r.ID
+			}
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("resolve variable set %q: %w", varset, err)
+	}
+	if found == "" {
+		return "", fmt.Errorf("resolve variable set %q: not found in organization %s", varset, org)
+	}
+	return found, nil
+}
+
+// resolveVarsetVarID resolves a variable ID or key within a variable set.
+func resolveVarsetVarID(client *api.Client, vsID, varKey string) (string, error) {
+	if strings.HasPrefix(varKey, "var-") {
+		return varKey, nil
+	}
+	vars, err := fetchVarsetVars(client, jsonapi.Resource{ID: vsID})
+	if err != nil {
+		return "", err
+	}
+	for _, v := range vars {
+		if v.Key == varKey {
+			return v.ID, nil
+		}
+	}
+	return "", fmt.Errorf("variable %q not found in variable set %s", varKey, vsID)
+}
+
+func runVarsetList(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	var resources []jsonapi.Resource
+	path := fmt.Sprintf("/organizations/%s/varsets?page[size]=100", org)
+	if err := client.GetAllPages(path, func(page []jsonapi.Resource) {
+		resources = append(resources, page...)
+	}); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	opts := GetOutputOptions()
+
+	type vsJSON struct {
+		ID    string      `json:"id"`
+		Attrs varsetAttrs `json:"attributes"`
+	}
+	var jsonData []vsJSON
+	td := output.TableData{
+		Headers: []string{"ID", "NAME", "GLOBAL", "PRIORITY", "VARS", "WORKSPACES", "PROJECTS", "UPDATED"},
+	}
+
+	for _, r := range resources {
+		var a varsetAttrs
+		jsonapi.UnmarshalAttributes(&r, &a)
+		td.Rows = append(td.Rows, []string{
+			r.ID, a.Name, boolStr(a.Global), boolStr(a.Priority), itoa(a.VarCount),
+			itoa(a.WorkspaceCount), itoa(a.ProjectCount), shortDate(a.UpdatedAt),
+		})
+		jsonData = append(jsonData, vsJSON{ID: r.ID, Attrs: a})
+	}
+
+	return output.RenderTable(td, jsonData, opts)
+}
+
+func runVarsetShow(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	vsID, err := resolveVarsetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Get("/varsets/"+vsID+"?include=workspaces,projects", &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	res, err := jsonapi.ParseSingle(&doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a varsetAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	// Map included workspaces and projects to names; fall back to IDs.
+	names := map[string]string{}
+	for _, inc := range doc.Included {
+		var n struct {
+			Name string `json:"name"`
+		}
+		jsonapi.UnmarshalAttributes(&inc, &n)
+		names[inc.ID] = n.Name
+	}
+
+	type attachment struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	toAttachments := func(ids []string) []attachment {
+		var out []attachment
+		for _, id := range ids {
+			out = append(out, attachment{ID: id, Name: names[id]})
+		}
+		return out
+	}
+	workspaces := toAttachments(extractRelationshipIDs(res, "workspaces"))
+	projects := toAttachments(extractRelationshipIDs(res, "projects"))
+
+	opts := GetOutputOptions()
+
+	type vsDetail struct {
+		ID         string       `json:"id"`
+		Attrs      varsetAttrs  `json:"attributes"`
+		Workspaces []attachment `json:"workspaces"`
+		Projects   []attachment `json:"projects"`
+	}
+	data := vsDetail{ID: res.ID, Attrs: a, Workspaces: workspaces, Projects: projects}
+
+	label := func(list []attachment) string {
+		var parts []string
+		for _, at := range list {
+			parts = append(parts, defaultStr(at.Name, at.ID))
+		}
+		return defaultStr(strings.Join(parts, ", "), "-")
+	}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Name", a.Name},
+			{"Description", defaultStr(a.Description, "-")},
+			{"Global", boolStr(a.Global)},
+			{"Priority", boolStr(a.Priority)},
+			{"Variables", itoa(a.VarCount)},
+			{"Workspaces", label(workspaces)},
+			{"Projects", label(projects)},
+			{"Updated", a.UpdatedAt},
+		},
+	}
+
+	return output.RenderTable(td, data, opts)
+}
+
+func runVarsetCreate(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	org, err := requireOrg()
+	if err != nil {
+		return err
+	}
+
+	description, _ := cmd.Flags().GetString("description")
+	global, _ := cmd.Flags().GetBool("global")
+	priority, _ := cmd.Flags().GetBool("priority")
+
+	attrs := map[string]interface{}{
+		"name":     args[0],
+		"global":   global,
+		"priority": priority,
+	}
+	if description != "" {
+		attrs["description"] = description
+	}
+
+	body, err := jsonapi.WrapForCreate("varsets", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post("/organizations/"+org+"/varsets", body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderVarsetResult(cmd, &doc, "created")
+}
+
+func runVarsetUpdate(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	vsID, err := resolveVarsetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	attrs := map[string]interface{}{}
+	if cmd.Flags().Changed("name") {
+		v, _ := cmd.Flags().GetString("name")
+		attrs["name"] = v
+	}
+	if cmd.Flags().Changed("description") {
+		v, _ := cmd.Flags().GetString("description")
+		attrs["description"] = v
+	}
+	if cmd.Flags().Changed("global") {
+		v, _ := cmd.Flags().GetBool("global")
+		attrs["global"] = v
+	}
+	if cmd.Flags().Changed("priority") {
+		v, _ := cmd.Flags().GetBool("priority")
+		attrs["priority"] = v
+	}
+	if len(attrs) == 0 {
+		return output.NewUsageError("nothing to update: pass at least one of --name, --description, --global, --priority")
+	}
+
+	body, err := jsonapi.WrapForUpdate(vsID, "varsets", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Patch("/varsets/"+vsID, body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderVarsetResult(cmd, &doc, "updated")
+}
+
+func renderVarsetResult(cmd *cobra.Command, doc *jsonapi.Document, verb string) error {
+	res, err := jsonapi.ParseSingle(doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a varsetAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	opts := GetOutputOptions()
+
+	type vsDetail struct {
+		ID    string      `json:"id"`
+		Attrs varsetAttrs `json:"attributes"`
+	}
+	data := vsDetail{ID: res.ID, Attrs: a}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Name", a.Name},
+			{"Description", defaultStr(a.Description, "-")},
+			{"Global", boolStr(a.Global)},
+			{"Priority", boolStr(a.Priority)},
+		},
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Variable set %s %s\n", res.ID, verb)
+	return output.RenderTable(td, data, opts)
+}
+
+func runVarsetDelete(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	vsID, err := resolveVarsetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if err := client.Delete("/varsets/" + vsID); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Variable set %s deleted\n", vsID)
+	return nil
+}
+
+// varsetTargets resolves the --workspace and --project flags to IDs.
+func varsetTargets(cmd *cobra.Command) (wsIDs, projIDs []string, err error) {
+	workspaces, _ := cmd.Flags().GetStringSlice("workspace")
+	projects, _ := cmd.Flags().GetStringSlice("project")
+	if len(workspaces) == 0 && len(projects) == 0 {
+		return nil, nil, output.NewUsageError("at least one --workspace or --project is required")
+	}
+
+	for _, w := range workspaces {
+		id, err := resolveWorkspaceID(w)
+		if err != nil {
+			return nil, nil, output.NewAPIError(err.Error())
+		}
+		wsIDs = append(wsIDs, id)
+	}
+	for _, p := range projects {
+		id, err := resolveProjectID(p)
+		if err != nil {
+			return nil, nil, output.NewAPIError(err.Error())
+		}
+		projIDs = append(projIDs, id)
+	}
+	return wsIDs, projIDs, nil
+}
+
+func runVarsetApply(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	vsID, err := resolveVarsetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	wsIDs, projIDs, err := varsetTargets(cmd)
+	if err != nil {
+		return err
+	}
+
+	if len(wsIDs) > 0 {
+		path := fmt.Sprintf("/varsets/%s/relationships/workspaces", vsID)
+		if err := client.Post(path, relationshipData("workspaces", wsIDs), nil); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	}
+	if len(projIDs) > 0 {
+		path := fmt.Sprintf("/varsets/%s/relationships/projects", vsID)
+		if err := client.Post(path, relationshipData("projects", projIDs), nil); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Variable set %s applied to %d workspace(s) and %d project(s)\n", vsID, len(wsIDs), len(projIDs))
+	return nil
+}
+
+func runVarsetRemove(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	vsID, err := resolveVarsetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	wsIDs, projIDs, err := varsetTargets(cmd)
+	if err != nil {
+		return err
+	}
+
+	if len(wsIDs) > 0 {
+		path := fmt.Sprintf("/varsets/%s/relationships/workspaces", vsID)
+		if err := client.DeleteWithBody(path, relationshipData("workspaces", wsIDs)); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	}
+	if len(projIDs) > 0 {
+		path := fmt.Sprintf("/varsets/%s/relationships/projects", vsID)
+		if err := client.DeleteWithBody(path, relationshipData("projects", projIDs)); err != nil {
+			return output.NewAPIError(err.Error())
+		}
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Variable set %s removed from %d workspace(s) and %d project(s)\n", vsID, len(wsIDs), len(projIDs))
+	return nil
+}
+
+func runVarsetVarList(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	vsID, err := resolveVarsetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	vars, err := fetchVarsetVars(client, jsonapi.Resource{ID: vsID})
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	opts := GetOutputOptions()
+
+	td := output.TableData{
+		Headers: []string{"ID", "KEY", "VALUE", "CATEGORY", "HCL", "SENSITIVE"},
+	}
+	for _, v := range vars {
+		value := v.Value
+		if v.Sensitive {
+			value = "(sensitive)"
+		}
+		td.Rows = append(td.Rows, []string{
+			v.ID, v.Key, truncateStr(value, 40), v.Category,
+			boolStr(v.HCL), boolStr(v.Sensitive),
+		})
+	}
+
+	return output.RenderTable(td, vars, opts)
+}
+
+func runVarsetVarAdd(cmd *cobra.Command, args []string) error {
+	key, _ := cmd.Flags().GetString("key")
+	if key == "" {
+		return output.NewUsageError("--key is required")
+	}
+	category, _ := cmd.Flags().GetString("category")
+	if category != "terraform" && category != "env" {
+		return output.NewUsageError(fmt.Sprintf("invalid --category %q: must be terraform or env", category))
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	vsID, err := resolveVarsetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	value, _ := cmd.Flags().GetString("value")
+	description, _ := cmd.Flags().GetString("description")
+	hcl, _ := cmd.Flags().GetBool("hcl")
+	sensitive, _ := cmd.Flags().GetBool("sensitive")
+
+	attrs := map[string]interface{}{
+		"key":         key,
+		"value":       value,
+		"description": description,
+		"category":    category,
+		"hcl":         hcl,
+		"sensitive":   sensitive,
+	}
+	body, err := jsonapi.WrapForCreate("vars", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Post(fmt.Sprintf("/varsets/%s/relationships/vars", vsID), body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderVarResult(cmd, &doc, "added to variable set "+vsID)
+}
+
+func runVarsetVarUpdate(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	vsID, err := resolveVarsetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	varID, err := resolveVarsetVarID(client, vsID, args[1])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	attrs := map[string]interface{}{}
+	for _, name := range []string{"key", "value", "description"} {
+		if cmd.Flags().Changed(name) {
+			v, _ := cmd.Flags().GetString(name)
+			attrs[name] = v
+		}
+	}
+	for _, name := range []string{"hcl", "sensitive"} {
+		if cmd.Flags().Changed(name) {
+			v, _ := cmd.Flags().GetBool(name)
+			attrs[name] = v
+		}
+	}
+	if len(attrs) == 0 {
+		return output.NewUsageError("nothing to update: pass at least one of --key, --value, --description, --hcl, --sensitive")
+	}
+
+	body, err := jsonapi.WrapForUpdate(varID, "vars", attrs)
+	if err != nil {
+		return output.NewInternalError(err.Error())
+	}
+
+	var doc jsonapi.Document
+	if err := client.Patch(fmt.Sprintf("/varsets/%s/relationships/vars/%s", vsID, varID), body, &doc); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	return renderVarResult(cmd, &doc, "updated")
+}
+
+func runVarsetVarDelete(cmd *cobra.Command, args []string) error {
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	vsID, err := resolveVarsetID(client, args[0])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+	varID, err := resolveVarsetVarID(client, vsID, args[1])
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	if err := client.Delete(fmt.Sprintf("/varsets/%s/relationships/vars/%s", vsID, varID)); err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Variable %s deleted from variable set %s\n", varID, vsID)
+	return nil
+}
+
+// renderVarResult renders a single variable returned by a create or update.
+func renderVarResult(cmd *cobra.Command, doc *jsonapi.Document, verb string) error {
+	res, err := jsonapi.ParseSingle(doc)
+	if err != nil {
+		return output.NewAPIError(err.Error())
+	}
+
+	var a varAttrs
+	jsonapi.UnmarshalAttributes(res, &a)
+
+	opts := GetOutputOptions()
+
+	type varDetail struct {
+		ID    string   `json:"id"`
+		Attrs varAttrs `json:"attributes"`
+	}
+	data := varDetail{ID: res.ID, Attrs: a}
+
+	value := a.Value
+	if a.Sensitive {
+		value = "(sensitive)"
+	}
+
+	td := output.TableData{
+		Headers: []string{"FIELD", "VALUE"},
+		Rows: [][]string{
+			{"ID", res.ID},
+			{"Key", a.Key},
+			{"Value", value},
+			{"Category", a.Category},
+			{"HCL", boolStr(a.HCL)},
+			{"Sensitive", boolStr(a.Sensitive)},
+		},
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "Variable %s %s\n", res.ID, verb)
+	return output.RenderTable(td, data, opts)
+}
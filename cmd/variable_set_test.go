@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestVarsetCreate_Body(t *testing.T) {
+	var capturedBody map[string]interface{}
+	var capturedPath string
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			capturedPath = r.URL.Path
+			json.NewDecoder(r.Body).Decode(&capturedBody)
+			w.WriteHeader(201)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{
+					"id":   "varset-new123",
+					"type": "varsets",
+					"attributes": map[string]interface{}{
+						"name":     "shared-aws",
+						"priority": true,
+					},
+				},
+			})
+			return
+		}
+		w.WriteHeader(404)
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"varset", "create", "shared-aws", "--org", "test-org", "--priority", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedPath != "/api/v2/organizations/test-org/varsets" {
+		t.Errorf("expected POST to organization varsets, got %s", capturedPath)
+	}
+	data := capturedBody["data"].(map[string]interface{})
+	attrs := data["attributes"].(map[string]interface{})
+	if attrs["name"] != "shared-aws" || attrs["priority"] != true || attrs["global"] != false {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+}
+
+func TestVarsetApply_ResolvesNames(t *testing.T) {
+	captured := map[string][]interface{}{}
+
+	ts := setupTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.api+json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/varsets":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"id": "varset-other", "type": "varsets", "attributes": map[string]interface{}{"name": "other"}},
+					map[string]interface{}{"id": "varset-abc", "type": "varsets", "attributes": map[string]interface{}{"name": "shared-aws"}},
+				},
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/workspaces/prod-app":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"id": "ws-prod", "type": "workspaces"},
+			})
+		case r.Method == "GET" && r.URL.Path == "/api/v2/organizations/test-org/projects":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": []interface{}{
+					map[string]interface{}{"id": "prj-infra", "type": "projects", "attributes": map[string]interface{}{"name": "infra"}},
+				},
+			})
+		case r.Method == "POST":
+			var body map[string][]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			captured[r.URL.Path] = body["data"]
+			w.WriteHeader(204)
+		default:
+			w.WriteHeader(404)
+		}
+	})
+	defer ts.Close()
+
+	t.Setenv("TFC_TOKEN", "test-token")
+	t.Setenv("TFC_ADDRESS", ts.URL)
+
+	cmd := rootCmd
+	cmd.SetArgs([]string{"varset", "apply", "shared-aws", "--org", "test-org", "--workspace", "prod-app,ws-staging", "--project", "infra"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ws := captured["/api/v2/varsets/varset-abc/relationships/workspaces"]
+	if len(ws) != 2 {
+		t.Fatalf("expected 2 workspaces attached, got %v", captured)
+	}
+	if ws[0].(map[string]interface{})["id"] != "ws-prod" || ws[1].(map[string]interface{})["id"] != "ws-staging" {
+		t.Errorf("unexpected workspace ids: %v", ws)
+	}
+	proj := captured["/api/v2/varsets/varset-abc/relationships/projects"]
+	if len(proj) != 1 || proj[0].(map[string]interface{})["id"] != "prj-infra" {
+		t.Errorf("unexpected project ids: %v", proj)
+	}
+}
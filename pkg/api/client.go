@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,6 +24,22 @@ type Client struct {
 	debug      func(string, ...interface{})
 }
 
+// StatusError is returned for non-2xx API responses other than 401 and 403.
+type StatusError struct {
+	StatusCode int
+	Detail     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Detail)
+}
+
+// IsNotFound reports whether err is an API 404 response.
+func IsNotFound(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode == http.StatusNotFound
+}
+
 // NewClient creates a new Terraform Cloud API client.
 func NewClient(baseURL, token string) *Client {
 	return &Client{
@@ -71,6 +88,12 @@ func (c *Client) Delete(path string) error {
 	return c.do("DELETE", path, nil, nil)
 }
 
+// DeleteWithBody performs a DELETE request with a JSON body, as used by
+// relationship endpoints that remove several members at once.
+func (c *Client) DeleteWithBody(path string, body interface{}) error {
+	return c.do("DELETE", path, body, nil)
+}
+
 // GetRaw performs a GET request and returns the raw response body.
 func (c *Client) GetRaw(path string) (io.ReadCloser, error) {
 	url := c.baseURL + path
@@ -94,12 +117,42 @@ func (c *Client) GetRaw(path string) (io.ReadCloser, error) {
 		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
 			return nil, formatAuthError(resp.StatusCode, path, detail)
 		}
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, detail)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Detail: detail}
 	}
 
 	return resp.Body, nil
 }
 
+// PutRaw performs a PUT request with a raw octet-stream body, as used by
+// upload endpoints such as policy code.
+func (c *Client) PutRaw(path string, body io.Reader) error {
+	url := c.baseURL + path
+	c.debugLog("%s %s (raw)", "PUT", url)
+
+	req, err := http.NewRequest("PUT", url, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		detail := truncate(string(respBody), 500)
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return formatAuthError(resp.StatusCode, path, detail)
+		}
+		return &StatusError{StatusCode: resp.StatusCode, Detail: detail}
+	}
+	return nil
+}
+
 func (c *Client) do(method, path string, body interface{}, result interface{}) error {
 	url := c.baseURL + path
 
@@ -156,7 +209,7 @@ func (c *Client) do(method, path string, body interface{}, result interface{}) e
 			return formatAuthError(resp.StatusCode, path, detail)
 		}
 
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, detail)
+		return &StatusError{StatusCode: resp.StatusCode, Detail: detail}
 	}
 
 	// 204 No Content — nothing to unmarshal
@@ -175,6 +228,19 @@ func (c *Client) do(method, path string, body interface{}, result interface{}) e
 
 // GetAllPages walks paginated results, calling collector for each page's data array.
 func (c *Client) GetAllPages(path string, collector func([]jsonapi.Resource)) error {
+	return c.GetAllDocuments(path, func(doc *jsonapi.Document) error {
+		resources, err := jsonapi.ParseList(doc)
+		if err != nil {
+			return err
+		}
+		collector(resources)
+		return nil
+	})
+}
+
+// GetAllDocuments walks paginated results, calling collector with each page's
+// full document so that sideloaded (included) resources are available.
+func (c *Client) GetAllDocuments(path string, collector func(*jsonapi.Document) error) error {
 	const maxPages = 100
 	for page := 1; page <= maxPages; page++ {
 		sep := "?"
@@ -188,11 +254,9 @@ func (c *Client) GetAllPages(path string, collector func([]jsonapi.Resource)) er
 			return err
 		}
 
-		resources, err := jsonapi.ParseList(&doc)
-		if err != nil {
+		if err := collector(&doc); err != nil {
 			return err
 		}
-		collector(resources)
 
 		if doc.Meta == nil || doc.Meta.Pagination == nil || doc.Meta.Pagination.NextPage == 0 || page >= doc.Meta.Pagination.TotalPages {
 			break
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -243,3 +245,39 @@ func TestDo_OtherErrors_Unchanged(t *testing.T) {
 		t.Errorf("non-auth errors should not have hints, got: %s", msg)
 	}
 }
+
+func TestIsNotFound(t *testing.T) {
+	status := http.StatusNotFound
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(`{"errors":[{"status":"error","title":"error","detail":"something went wrong"}]}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, "some-token")
+	client.baseURL = srv.URL
+
+	err := client.Get("/workspaces/ws-notexist", nil)
+	var se *StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a *StatusError with status 404, got: %v", err)
+	}
+	if !IsNotFound(err) {
+		t.Errorf("expected IsNotFound to report a 404, got: %v", err)
+	}
+	if !IsNotFound(fmt.Errorf("resolve workspace: %w", err)) {
+		t.Error("expected IsNotFound to see through wrapping")
+	}
+
+	status = http.StatusConflict
+	err = client.Get("/workspaces/ws-locked", nil)
+	if !errors.As(err, &se) || se.StatusCode != http.StatusConflict {
+		t.Fatalf("expected a *StatusError with status 409, got: %v", err)
+	}
+	if IsNotFound(err) {
+		t.Errorf("IsNotFound should be false for a 409, got true for: %v", err)
+	}
+	if IsNotFound(nil) || IsNotFound(errors.New("not found")) {
+		t.Error("IsNotFound should be false for nil and non-API errors")
+	}
+}
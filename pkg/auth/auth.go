@@ -22,3 +22,12 @@ func GetAddress() string {
 	}
 	return "https://app.terraform.io"
 }
+
+// GetAuditToken returns the token for the audit trail API, which only accepts
+// organization tokens: TFC_AUDIT_TOKEN if set, otherwise TFC_TOKEN.
+func GetAuditToken() (string, error) {
+	if token := strings.TrimSpace(os.Getenv("TFC_AUDIT_TOKEN")); token != "" {
+		return token, nil
+	}
+	return GetToken()
+}
@@ -14,6 +14,7 @@ const (
 	ErrTypePermission    = "permission_error"
 	ErrTypeInternalError = "internal_error"
 	ErrTypeTimeout       = "timeout"
+	ErrTypePlanChanges   = "plan_has_changes"
 )
 
 // StructuredError represents a machine-readable error with a stable type field.